@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"main/lambdas/fret-placement-calculator-api/handler"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run calculates fret placements offline by putting the flags to the same handler as the web service.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fretcalc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	scaleLength := flags.Float64("scale-length", 0, "scale length from nut to bridge (saddle)")
+	var ids []string
+	for _, system := range handler.TuningSystems() {
+		ids = append(ids, system.ID)
+	}
+	tuningSystem := flags.String("tuning-system", "", "tuning system ("+strings.Join(ids, ", ")+")")
+	octaves := flags.Int("octaves", 0, "number of periods of frets to compute: octaves, unless the tuning system repeats at another interval")
+	parameters := tuningSystemFlags(flags)
+	units := flags.String("units", "", "units of the scale length (mm, cm or in)")
+	format := flags.String("format", "table", "output format: table, json, csv, tsv or svg")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	q := map[string]string{
+		"scaleLength":  strconv.FormatFloat(*scaleLength, 'f', -1, 64),
+		"tuningSystem": *tuningSystem,
+		"units":        *units,
+		"format":       *format,
+	}
+	if *octaves != 0 {
+		q["octaves"] = strconv.Itoa(*octaves)
+	}
+	for name, value := range parameters {
+		if *value != "" {
+			q[name] = *value
+		}
+	}
+	if *format == "table" {
+		q["format"] = "json"
+	}
+
+	response, err := handler.Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if response.StatusCode != http.StatusOK {
+		_, _ = fmt.Fprintln(stderr, response.Body)
+		return 1
+	}
+
+	if *format == "table" {
+		var fretboard handler.DetailedFretboard
+		if err := json.Unmarshal([]byte(response.Body), &fretboard); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+		printTable(stdout, fretboard)
+		return 0
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		body, _ = base64.StdEncoding.DecodeString(response.Body)
+	}
+	_, _ = stdout.Write(body)
+	return 0
+}
+
+// tuningSystemFlags offers a flag for every parameter of every registered tuning system, named as the parameter is but
+// in kebab case, so that --diatonic-mode sets diatonicMode.  Parameters shared by several systems share a flag.
+func tuningSystemFlags(flags *flag.FlagSet) map[string]*string {
+	systems := map[string][]string{}
+	var parameters []handler.Parameter
+	for _, system := range handler.TuningSystems() {
+		for _, p := range system.Parameters {
+			if _, seen := systems[p.Name]; !seen {
+				parameters = append(parameters, p)
+			}
+			systems[p.Name] = append(systems[p.Name], system.ID)
+		}
+	}
+
+	values := map[string]*string{}
+	for _, p := range parameters {
+		usage := fmt.Sprintf("%s, for %s", p.Description, strings.Join(systems[p.Name], " and "))
+		values[p.Name] = flags.String(kebabCase(p.Name), "", usage)
+	}
+	return values
+}
+
+func kebabCase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteRune('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func printTable(w io.Writer, fretboard handler.DetailedFretboard) {
+	_, _ = fmt.Fprintf(w, "%s\n%s\nScale length: %g\n\n", fretboard.System, fretboard.Description, fretboard.ScaleLength)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Fret\tLabel\tPosition\tInterval\tComment")
+	row := "%d\t%s\t" + handler.LengthFormat(fretboard.Units) + "\t%s\t%s\n"
+	for i, fret := range fretboard.Frets {
+		_, _ = fmt.Fprintf(tw, row, i, fret.Label, fret.Position, fret.Interval, fret.Comment)
+	}
+	_ = tw.Flush()
+}
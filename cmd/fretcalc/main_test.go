@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldPrintHumanReadableTableByDefault(t *testing.T) {
+	// Given
+	var stdout, stderr bytes.Buffer
+
+	// When
+	status := run([]string{"--scale-length", "540", "--tuning-system", "ptolemy"}, &stdout, &stderr)
+
+	// Then
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "", stderr.String())
+	assert.Equal(t, `Ptolemy Intense Diatonic
+Fret positions based on Ptolemy's 5-limit intense diatonic scale in Ionian mode.
+Scale length: 540
+
+Fret  Label  Position  Interval  Comment
+0     1:1    0.00      1:1       Perfect Unison
+1     9:8    60.00     9:8       Pythagorean (Greater) Major Second
+2     5:4    108.00    10:9      Major Third
+3     4:3    135.00    16:15     Perfect Fourth
+4     3:2    180.00    9:8       Perfect Fifth
+5     5:3    216.00    10:9      Major Sixth
+6     15:8   252.00    9:8       Just Major Seventh
+7     2:1    270.00    16:15     Perfect Octave
+`, stdout.String())
+}
+
+func Test_ShouldPrintOtherFormatsFromTheHandler(t *testing.T) {
+	// Given
+	var stdout, stderr bytes.Buffer
+
+	// When
+	status := run([]string{"--scale-length=540", "--tuning-system=ptolemy", "--diatonic-mode=Lydian", "--format=csv"}, &stdout, &stderr)
+
+	// Then
+	assert.Equal(t, 0, status)
+	assert.Contains(t, stdout.String(), "3,45:32,156.00,48.00,384.00,9:8,Augmented Fourth\n")
+}
+
+func Test_ShouldReportErrorsOnStandardError(t *testing.T) {
+	// Given
+	var stdout, stderr bytes.Buffer
+
+	// When
+	status := run([]string{"--scale-length", "540", "--tuning-system", "kazoo"}, &stdout, &stderr)
+
+	// Then
+	assert.Equal(t, 1, status)
+	assert.Equal(t, "", stdout.String())
+	assert.True(t, strings.HasPrefix(stderr.String(), `{"errors":[{"code":"not_allowed","parameter":"tuningSystem","reason":"must be one of the allowed values"`))
+	assert.True(t, strings.HasSuffix(stderr.String(), "}]}\n"))
+}
+
+func Test_ShouldTakeTheParametersOfEveryTuningSystemAsFlags(t *testing.T) {
+	// Given
+	var stdout, stderr bytes.Buffer
+
+	// When
+	status := run([]string{"--scale-length=600", "--tuning-system=custom", "--intervals=5:4,3:2", "--period=3:1", "--format=csv"}, &stdout, &stderr)
+
+	// Then
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "", stderr.String())
+	assert.Contains(t, stdout.String(), "2,3:2,200.00,")
+	assert.Contains(t, stdout.String(), "3,3:1,400.00,")
+}
+
+func Test_ShouldListEveryRegisteredTuningSystemInTheHelp(t *testing.T) {
+	// Given
+	var stdout, stderr bytes.Buffer
+
+	// When
+	status := run([]string{"--help"}, &stdout, &stderr)
+
+	// Then
+	assert.Equal(t, 2, status)
+	assert.Contains(t, stderr.String(), "tuning system (justFromRatios, just5limitFromPythagorean, meantone, extendedMeantone, bachWellTemperament, pythagorean, equal, ptolemy, saz, regular, custom, scala)")
+	assert.Contains(t, stderr.String(), "-generators-up string")
+	assert.Contains(t, stderr.String(), "Interval at which the scale repeats, as a ratio or in cents, for regular and custom")
+}
+
+func Test_ShouldPrintPositionsInInchesToThousandths(t *testing.T) {
+	// Given
+	var stdout, stderr bytes.Buffer
+
+	// When
+	status := run([]string{"--scale-length=25.5", "--units=in", "--tuning-system=equal", "--divisions=12"}, &stdout, &stderr)
+
+	// Then
+	assert.Equal(t, 0, status)
+	assert.Contains(t, stdout.String(), "1     100.00 cents   1.431 ")
+	assert.Contains(t, stdout.String(), "12    1200.00 cents  12.750 ")
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const maximumBatchSize = 100
+
+// BatchResult is the response one calculation of a batch would have had if it had been requested on its own.  JSON
+// bodies, fretboards and errors alike, are included as they are; other formats as text, or base64 if binary.
+type BatchResult struct {
+	StatusCode      int    `json:"statusCode"`
+	ContentType     string `json:"contentType"`
+	Body            any    `json:"body"`
+	IsBase64Encoded bool   `json:"isBase64Encoded,omitempty"`
+}
+
+// handleBatchRequest works out every calculation in a JSON array of them POSTed as the body, each an object of the
+// same parameters as a GET request, returning their results in the same order.  Parameters in the query string apply
+// to every calculation that doesn't give its own.
+func (h Handler) handleBatchRequest(request events.LambdaFunctionURLRequest) events.LambdaFunctionURLResponse {
+	body, err := bodyOf(request)
+	if err != nil {
+		return invalidBatchResponse()
+	}
+	var calculations []json.RawMessage
+	if err := decodeJSON(body, &calculations); err != nil || calculations == nil {
+		return invalidBatchResponse()
+	}
+	if len(calculations) > maximumBatchSize {
+		return validationErrorResponse(ValidationError{Code: OutOfRangeError, Parameter: "body", Reason: fmt.Sprintf("must hold no more than %d calculations", maximumBatchSize)})
+	}
+
+	results := []BatchResult{}
+	for _, calculation := range calculations {
+		q, errors := batchParametersFrom(calculation, request.QueryStringParameters)
+		response := validationErrorResponse(errors...)
+		if len(errors) == 0 {
+			response = h.calculate(q, "")
+		}
+		results = append(results, newBatchResult(response))
+	}
+	return jsonResponse(results)
+}
+
+// batchParametersFrom reads the parameters of one calculation of a batch, so that one that isn't an object of them
+// fails on its own rather than failing the whole batch.
+func batchParametersFrom(calculation json.RawMessage, q map[string]string) (map[string]string, []ValidationError) {
+	var object map[string]any
+	if decodeJSON(calculation, &object) != nil || object == nil {
+		return nil, []ValidationError{{Code: InvalidTypeError, Parameter: "body", Reason: "must be a JSON object of parameters"}}
+	}
+	parameters, errors := parametersFromJSON(object)
+	applyDefaults(parameters, q)
+	return parameters, errors
+}
+
+func invalidBatchResponse() events.LambdaFunctionURLResponse {
+	return validationErrorResponse(ValidationError{Code: InvalidTypeError, Parameter: "body", Reason: "must be a JSON array of calculations, each an object of parameters"})
+}
+
+func newBatchResult(response events.LambdaFunctionURLResponse) BatchResult {
+	result := BatchResult{StatusCode: response.StatusCode, ContentType: response.Headers["Content-Type"], Body: response.Body, IsBase64Encoded: response.IsBase64Encoded}
+	if result.ContentType == "application/json" {
+		result.Body = json.RawMessage(response.Body)
+	}
+	return result
+}
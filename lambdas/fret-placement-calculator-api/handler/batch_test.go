@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+	"github.com/stretchr/testify/assert"
+)
+
+func batchRequest(body string, q map[string]string) events.LambdaFunctionURLRequest {
+	return events.LambdaFunctionURLRequest{
+		RawPath:               "/batch",
+		QueryStringParameters: q,
+		Body:                  body,
+		RequestContext:        events.LambdaFunctionURLRequestContext{HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodPost}},
+	}
+}
+
+func Test_ShouldCalculateEveryFretboardOfABatchInOrder(t *testing.T) {
+	// Given
+	body := `[
+		{"scaleLength": 540, "tuningSystem": "saz"},
+		{"scaleLength": 650, "tuningSystem": "equal", "divisions": 0},
+		{"scaleLength": 25.5, "units": "in", "tuningSystem": "equal", "divisions": 12, "format": "csv"}
+	]`
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), batchRequest(body, nil))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var results []struct {
+		StatusCode  int             `json:"statusCode"`
+		ContentType string          `json:"contentType"`
+		Body        json.RawMessage `json:"body"`
+	}
+	_ = json.Unmarshal([]byte(response.Body), &results)
+	assert.Equal(t, 3, len(results))
+
+	assert.Equal(t, http.StatusOK, results[0].StatusCode)
+	assert.Equal(t, "application/json", results[0].ContentType)
+	var fretboard instruments.Fretboard
+	_ = json.Unmarshal(results[0].Body, &fretboard)
+	assert.Equal(t, "Saz", fretboard.System)
+	assert.Equal(t, 540.0, fretboard.ScaleLength)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, results[1].StatusCode)
+	assert.JSONEq(t, `{"errors":[{"code":"out_of_range","parameter":"divisions","reason":"must be between 1 and 1200"}]}`, string(results[1].Body))
+
+	assert.Equal(t, http.StatusOK, results[2].StatusCode)
+	assert.Equal(t, "text/csv", results[2].ContentType)
+	var table string
+	_ = json.Unmarshal(results[2].Body, &table)
+	assert.True(t, strings.HasPrefix(table, "fret,label,"))
+}
+
+func Test_ShouldApplyQueryStringParametersToEveryCalculationOfABatchThatDoesNotGiveItsOwn(t *testing.T) {
+	// Given
+	body := `[{"tuningSystem": "saz"}, {"tuningSystem": "saz", "scaleLength": 600}]`
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), batchRequest(body, map[string]string{"scaleLength": "540"}))
+
+	// Then
+	var results []struct {
+		Body instruments.Fretboard `json:"body"`
+	}
+	_ = json.Unmarshal([]byte(response.Body), &results)
+	assert.Equal(t, 540.0, results[0].Body.ScaleLength)
+	assert.Equal(t, 600.0, results[1].Body.ScaleLength)
+}
+
+func Test_ShouldReturnBinaryResultsOfABatchBase64Encoded(t *testing.T) {
+	// Given
+	body := `[{"scaleLength": 540, "tuningSystem": "saz", "format": "pdf"}]`
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), batchRequest(body, nil))
+
+	// Then
+	var results []BatchResult
+	_ = json.Unmarshal([]byte(response.Body), &results)
+	assert.Equal(t, "application/pdf", results[0].ContentType)
+	assert.True(t, results[0].IsBase64Encoded)
+	assert.True(t, strings.HasPrefix(results[0].Body.(string), "JVBERi0xLjQK"))
+}
+
+func Test_ShouldReportCalculationsOfABatchThatAreNotObjectsAsTheirOwnErrors(t *testing.T) {
+	// Given
+	body := `[540, {"scaleLength": 540, "tuningSystem": "saz"}, null]`
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), batchRequest(body, nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var results []struct {
+		StatusCode int             `json:"statusCode"`
+		Body       json.RawMessage `json:"body"`
+	}
+	_ = json.Unmarshal([]byte(response.Body), &results)
+	assert.Equal(t, 3, len(results))
+	for _, i := range []int{0, 2} {
+		assert.Equal(t, http.StatusUnprocessableEntity, results[i].StatusCode)
+		assert.JSONEq(t, `{"errors":[{"code":"invalid_type","parameter":"body","reason":"must be a JSON object of parameters"}]}`, string(results[i].Body))
+	}
+	assert.Equal(t, http.StatusOK, results[1].StatusCode)
+}
+
+func Test_ShouldRejectBatchesThatAreNotArraysOfCalculations(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: `{"errors":[{"code":"invalid_type","parameter":"body","reason":"must be a JSON array of calculations, each an object of parameters"}]}`},
+		{name: "object", body: `{"scaleLength": 540}`, want: `{"errors":[{"code":"invalid_type","parameter":"body","reason":"must be a JSON array of calculations, each an object of parameters"}]}`},
+		{name: "too many", body: "[" + strings.Repeat(`{},`, maximumBatchSize) + "{}]", want: `{"errors":[{"code":"out_of_range","parameter":"body","reason":"must hold no more than 100 calculations"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), batchRequest(tt.body, nil))
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.want}, response)
+		})
+	}
+}
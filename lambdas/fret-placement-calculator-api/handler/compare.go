@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type ComparedSystem struct {
+	ID          string `json:"id"`
+	System      string `json:"system"`
+	Description string `json:"description,omitempty"`
+}
+
+// ComparedFret is the fret of one tuning system nearest in pitch to a fret of the first, with the differences from it.
+type ComparedFret struct {
+	Fret               int     `json:"fret"`
+	Label              string  `json:"label"`
+	Position           float64 `json:"position"`
+	Cents              float64 `json:"cents"`
+	PositionDifference float64 `json:"positionDifference"`
+	CentsDifference    float64 `json:"centsDifference"`
+}
+
+// ComparedDegree lines up one fret of each tuning system, in the order the systems were given.
+type ComparedDegree struct {
+	Frets []ComparedFret `json:"frets"`
+}
+
+type Comparison struct {
+	ScaleLength   float64          `json:"scaleLength"`
+	Units         string           `json:"units"`
+	TuningSystems []ComparedSystem `json:"tuningSystems"`
+	Degrees       []ComparedDegree `json:"degrees"`
+}
+
+var compareParameters = []Parameter{
+	{Name: "systems", Type: StringParameter, Description: "Comma-separated tuning systems to compare, each optionally followed by its parameters in brackets, such as meantone,equal(divisions=19),ptolemy(diatonicMode=Dorian)", Required: true},
+}
+
+// splitSystems splits a list of tuning systems at the commas between them, leaving those within brackets alone.
+func splitSystems(s string) []string {
+	var systems []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				systems = append(systems, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(systems, s[start:])
+}
+
+// parseSystem reads a tuning system such as equal(divisions=19;octaves=2) into the parameters it stands for.
+func parseSystem(s string) (map[string]string, bool) {
+	id, rest, hasParameters := strings.Cut(strings.TrimSpace(s), "(")
+	q := map[string]string{"tuningSystem": strings.TrimSpace(id)}
+	if !hasParameters {
+		return q, true
+	}
+	rest, closed := strings.CutSuffix(strings.TrimSpace(rest), ")")
+	if !closed {
+		return nil, false
+	}
+	for _, parameter := range strings.Split(rest, ";") {
+		if strings.TrimSpace(parameter) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(parameter, "=")
+		if !ok {
+			return nil, false
+		}
+		q[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return q, true
+}
+
+type comparedFretboard struct {
+	system ComparedSystem
+	frets  []ComparedFret
+}
+
+// parseComparedParameters parses the common parameters needed to compare tuning systems, leaving out the tuning system
+// unless asked for.
+func (v *validator) parseComparedParameters(withTuningSystem bool) {
+	for _, p := range commonParameters() {
+		if p.Name != "format" && (p.Name != "tuningSystem" || withTuningSystem) {
+			v.parse(p)
+		}
+	}
+	v.checkMaximumPosition()
+}
+
+func (h Handler) handleCompareRequest(q map[string]string) events.LambdaFunctionURLResponse {
+	v := newValidator(q)
+	v.parseComparedParameters(false)
+	v.parse(compareParameters...)
+	if !v.valid() {
+		return v.errorResponse()
+	}
+
+	var fretboards []comparedFretboard
+	for i, spec := range splitSystems(v.args.text("systems")) {
+		sq, ok := parseSystem(spec)
+		if !ok {
+			v.addError(ValidationError{Code: InvalidTypeError, Parameter: fmt.Sprintf("systems[%d]", i), Reason: "must be a tuning system optionally followed by name=value parameters separated by semicolons in brackets"})
+			continue
+		}
+		applyDefaults(sq, q)
+
+		sv := newValidator(sq)
+		sv.parseComparedParameters(true)
+		system, _ := sv.parseTuningSystem()
+		for _, err := range sv.errors {
+			err.Parameter = fmt.Sprintf("systems[%d].%s", i, err.Parameter)
+			v.addError(err)
+		}
+		if sv.valid() {
+			fretboards = append(fretboards, newComparedFretboard(system, sv.args))
+		}
+	}
+	if v.valid() && len(fretboards) < 2 {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "systems", Reason: "must name at least two tuning systems"})
+	}
+	if !v.valid() {
+		return v.errorResponse()
+	}
+
+	comparison := newComparison(v.args.number("scaleLength"), v.args.text("units"), fretboards)
+	return jsonResponse(comparison)
+}
+
+func newComparedFretboard(system TuningSystem, args arguments) comparedFretboard {
+	scale := system.scale(args.integer("octaves"), args)
+	fretboard := scale.fretboard(args.number("scaleLength"), args.text("units"))
+	compared := comparedFretboard{system: ComparedSystem{ID: system.ID, System: fretboard.System, Description: fretboard.Description}}
+	for i, ratio := range scale.ratios() {
+		compared.frets = append(compared.frets, ComparedFret{
+			Fret:     i,
+			Label:    fretboard.Frets[i].Label,
+			Position: fretboard.Frets[i].Position,
+			Cents:    1200 * math.Log2(ratio),
+		})
+	}
+	return compared
+}
+
+// newComparison takes each fret of the first tuning system in turn and lines up with it the fret of every other
+// system that is nearest to it in pitch.
+func newComparison(scaleLength float64, units string, fretboards []comparedFretboard) Comparison {
+	comparison := Comparison{ScaleLength: scaleLength, Units: units}
+	for _, fretboard := range fretboards {
+		comparison.TuningSystems = append(comparison.TuningSystems, fretboard.system)
+	}
+
+	for _, reference := range fretboards[0].frets {
+		var degree ComparedDegree
+		for _, fretboard := range fretboards {
+			nearest := fretboard.frets[0]
+			for _, fret := range fretboard.frets {
+				if math.Abs(fret.Cents-reference.Cents) < math.Abs(nearest.Cents-reference.Cents) {
+					nearest = fret
+				}
+			}
+			nearest.PositionDifference = roundToPrecisionOf(nearest.Position-reference.Position, units)
+			nearest.CentsDifference = roundToHundredths(nearest.Cents - reference.Cents)
+			nearest.Cents = roundToHundredths(nearest.Cents)
+			degree.Frets = append(degree.Frets, nearest)
+		}
+		comparison.Degrees = append(comparison.Degrees, degree)
+	}
+	return comparison
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitSystems(t *testing.T) {
+	assert.Equal(t, []string{"meantone", "equal(divisions=19)", "custom(intervals=7:6,3:2;period=3:1)"}, splitSystems("meantone,equal(divisions=19),custom(intervals=7:6,3:2;period=3:1)"))
+	assert.Equal(t, []string{"saz"}, splitSystems("saz"))
+}
+
+func Test_parseSystem(t *testing.T) {
+	tests := []struct {
+		input string
+		want  map[string]string
+		ok    bool
+	}{
+		{input: "meantone", want: map[string]string{"tuningSystem": "meantone"}, ok: true},
+		{input: " equal( divisions = 19 ; octaves=2 ) ", want: map[string]string{"tuningSystem": "equal", "divisions": "19", "octaves": "2"}, ok: true},
+		{input: "equal()", want: map[string]string{"tuningSystem": "equal"}, ok: true},
+		{input: "equal(divisions=19", ok: false},
+		{input: "equal(19)", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseSystem(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ShouldCompareTuningSystemsFretByFret(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		RawPath:               "/compare",
+		QueryStringParameters: map[string]string{"scaleLength": "600", "systems": "meantone,bachWellTemperament"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var comparison Comparison
+	_ = json.Unmarshal([]byte(response.Body), &comparison)
+	assert.Equal(t, 600.0, comparison.ScaleLength)
+	assert.Equal(t, "meantone", comparison.TuningSystems[0].ID)
+	assert.Equal(t, "Bach's Well-Tempered Tuning", comparison.TuningSystems[1].System)
+	assert.Equal(t, 14, len(comparison.Degrees))
+	assert.Equal(t, ComparedDegree{Frets: []ComparedFret{
+		{Fret: 1, Label: "117.13 cents", Position: 39.25, Cents: 117.13},
+		{Fret: 1, Label: "102.51 cents", Position: 34.5, Cents: 102.51, PositionDifference: -4.75, CentsDifference: -14.62},
+	}}, comparison.Degrees[1])
+}
+
+func Test_ShouldLineUpTuningSystemsWithDifferentNumbersOfFretsByPitch(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		RawPath:               "/compare/",
+		QueryStringParameters: map[string]string{"scaleLength": "600", "systems": "ptolemy,equal(divisions=12),equal(divisions=19)"},
+	})
+
+	// Then
+	var comparison Comparison
+	_ = json.Unmarshal([]byte(response.Body), &comparison)
+	assert.Equal(t, 8, len(comparison.Degrees))
+	fifth := comparison.Degrees[4].Frets
+	assert.Equal(t, ComparedFret{Fret: 4, Label: "3:2", Position: 200, Cents: 701.96}, fifth[0])
+	assert.Equal(t, ComparedFret{Fret: 7, Label: "700.00 cents", Position: 199.55, Cents: 700, PositionDifference: -0.45, CentsDifference: -1.96}, fifth[1])
+	assert.Equal(t, ComparedFret{Fret: 11, Label: "694.74 cents", Position: 198.33, Cents: 694.74, PositionDifference: -1.67, CentsDifference: -7.22}, fifth[2])
+}
+
+func Test_ShouldCompareNonOctaveTuningSystemsUpToTheMaximumPosition(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		RawPath:               "/compare",
+		QueryStringParameters: map[string]string{"scaleLength": "600", "maximumPosition": "400", "systems": "equal(divisions=13;interval=3:1),equal(stepSize=146.3)"},
+	})
+
+	// Then
+	var comparison Comparison
+	_ = json.Unmarshal([]byte(response.Body), &comparison)
+	assert.Equal(t, 14, len(comparison.Degrees))
+	assert.Equal(t, ComparedDegree{Frets: []ComparedFret{
+		{Fret: 13, Label: "3:1", Position: 400, Cents: 1901.96},
+		{Fret: 13, Label: "1901.90 cents", Position: 399.99, Cents: 1901.9, PositionDifference: -0.01, CentsDifference: -0.06},
+	}}, comparison.Degrees[13])
+}
+
+func Test_ShouldReturnErrorsForEachInvalidComparedSystem(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "missing systems",
+			query: map[string]string{"scaleLength": "600"},
+			body:  `{"errors":[{"code":"required","parameter":"systems","reason":"is required"}]}`,
+		},
+		{
+			name:  "single system",
+			query: map[string]string{"scaleLength": "600", "systems": "saz"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"systems","reason":"must name at least two tuning systems"}]}`,
+		},
+		{
+			name:  "invalid systems",
+			query: map[string]string{"scaleLength": "600", "systems": "equal(divisions=0),saz,equal(divisions"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"systems[0].divisions","reason":"must be between 1 and 1200"},{"code":"invalid_type","parameter":"systems[2]","reason":"must be a tuning system optionally followed by name=value parameters separated by semicolons in brackets"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{RawPath: "/compare", QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
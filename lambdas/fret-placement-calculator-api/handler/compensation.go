@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mikebharris/music/instruments"
+)
+
+// playableFraction is how far along the scale frets can be reached, as far as the 24th fret of a 12-tone instrument.
+const playableFraction = 0.75
+
+// Compensation gives how far to move the nut towards the bridge and the saddle away from the nut so that a real
+// string, which goes sharp when fretted, plays as nearly in tune as it can at every fret.
+type Compensation struct {
+	NutOffset    float64           `json:"nutOffset"`
+	SaddleOffset float64           `json:"saddleOffset"`
+	Frets        []CompensatedFret `json:"frets"`
+}
+
+// CompensatedFret gives the pitch error in cents of a fretted note, before and after compensation.
+type CompensatedFret struct {
+	Fret               int     `json:"fret"`
+	UncompensatedError float64 `json:"uncompensatedError"`
+	Error              float64 `json:"error"`
+}
+
+var compensationParameters = []Parameter{
+	{Name: "nutAction", Type: NumberParameter, Description: "Height of the string above the fret tops at the nut", Required: true, Minimum: bound(0)},
+	{Name: "twelfthFretAction", Type: NumberParameter, Description: "Height of the string above the fret tops halfway along the scale, where the 12th fret of a 12-tone instrument lies", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "gauge", Type: NumberParameter, Description: "Diameter of the string", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "material", Type: StringParameter, Description: "What the string is made of", Default: "plainSteel", AllowedValues: stringMaterialIDs()},
+	{Name: "tension", Type: NumberParameter, Description: "Tension of the open string in newtons", Required: true, ExclusiveMinimum: bound(0)},
+}
+
+func isCompensationRequest(q map[string]string) bool {
+	return slices.ContainsFunc(compensationParameters, func(p Parameter) bool { return q[p.Name] != "" })
+}
+
+func (v *validator) parseCompensation() {
+	numberOfErrors := len(v.errors)
+	v.parse(compensationParameters...)
+	if len(v.errors) == numberOfErrors && 2*v.args.number("twelfthFretAction") <= v.args.number("nutAction") {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "twelfthFretAction", Reason: "must be more than half of nutAction, or the string would lie on the frets"})
+	}
+}
+
+// fretStringModel describes a string stretched over a fretboard, in SI units.  The string runs straight from the nut
+// to the saddle over frets whose tops lie in a plane at height zero.
+type fretStringModel struct {
+	scaleLength  float64
+	nutHeight    float64
+	saddleHeight float64
+	diameter     float64
+	tension      float64
+	material     stringMaterial
+}
+
+func newFretStringModel(args arguments, scaleLength float64, units string) fretStringModel {
+	metres := metresPer(units)
+	nutHeight := args.number("nutAction") * metres
+	return fretStringModel{
+		scaleLength:  scaleLength * metres,
+		nutHeight:    nutHeight,
+		saddleHeight: 2*args.number("twelfthFretAction")*metres - nutHeight,
+		diameter:     args.number("gauge") * metres,
+		tension:      args.number("tension"),
+		material:     lookupStringMaterial(args.text("material")),
+	}
+}
+
+// pitchError is how far in cents a note fretted at x sounds from the ratio the fret was placed for, with the nut
+// moved towards the bridge by nutOffset and the saddle away from it by saddleOffset.  Fretting both lengthens the
+// string, raising its tension, and shortens the vibrating length more than the ideal, and the string's stiffness
+// raises the pitch further the shorter it gets.
+func (m fretStringModel) pitchError(x, nutOffset, saddleOffset float64) float64 {
+	open := math.Hypot(m.scaleLength+saddleOffset-nutOffset, m.saddleHeight-m.nutHeight)
+	vibrating := math.Hypot(m.scaleLength+saddleOffset-x, m.saddleHeight)
+	stretch := math.Hypot(x-nutOffset, m.nutHeight) + vibrating - open
+	tension := m.tension + m.material.stretchStiffness(m.diameter)*stretch/open
+	ratio := open / vibrating * math.Sqrt(tension/m.tension) * m.stiffening(vibrating, tension) / m.stiffening(open, m.tension)
+	return 1200 * math.Log2(ratio*(m.scaleLength-x)/m.scaleLength)
+}
+
+// stiffening is the factor by which bending stiffness raises the fundamental of a string clamped at both ends.
+func (m fretStringModel) stiffening(length, tension float64) float64 {
+	return 1 + 2*math.Sqrt(m.material.bendingStiffness(m.diameter)/tension)/length
+}
+
+// compensate finds the nut and saddle offsets that minimise the sum of the squares of the pitch errors at the given
+// fret positions, by Gauss-Newton iteration as the errors are very nearly linear in the offsets.
+func (m fretStringModel) compensate(positions []float64) (nutOffset, saddleOffset float64) {
+	const step = 1e-6
+	for range 5 {
+		var nn, ns, ss, ne, se float64
+		for _, x := range positions {
+			e := m.pitchError(x, nutOffset, saddleOffset)
+			dn := (m.pitchError(x, nutOffset+step, saddleOffset) - e) / step
+			ds := (m.pitchError(x, nutOffset, saddleOffset+step) - e) / step
+			nn, ns, ss, ne, se = nn+dn*dn, ns+dn*ds, ss+ds*ds, ne+dn*e, se+ds*e
+		}
+		determinant := nn*ss - ns*ns
+		if determinant <= 1e-9*nn*ss {
+			// too few frets to tell the nut from the saddle, so leave the nut where it is
+			saddleOffset -= se / ss
+			continue
+		}
+		nutOffset -= (ss*ne - ns*se) / determinant
+		saddleOffset -= (nn*se - ns*ne) / determinant
+	}
+	return nutOffset, saddleOffset
+}
+
+// newCompensation fits the offsets to the frets within reach of a player, as those further up, which on fretboards of
+// many octaves reach the bridge itself, would pull the fit away from the frets that are played.
+func newCompensation(args arguments, fretboard instruments.Fretboard, units string) (*Compensation, *ValidationError) {
+	model := newFretStringModel(args, fretboard.ScaleLength, units)
+	metres := metresPer(units)
+	var positions []float64
+	for _, fret := range fretboard.Frets[1:] {
+		if fret.Position <= playableFraction*fretboard.ScaleLength {
+			positions = append(positions, fret.Position*metres)
+		}
+	}
+	if len(positions) == 0 {
+		return nil, &ValidationError{Code: OutOfRangeError, Parameter: "tuningSystem", Reason: "must have frets within three quarters of the scale length to be compensated"}
+	}
+
+	nutOffset, saddleOffset := model.compensate(positions)
+	if math.IsNaN(nutOffset) || math.IsInf(nutOffset, 0) || math.IsNaN(saddleOffset) || math.IsInf(saddleOffset, 0) {
+		return nil, &ValidationError{Code: OutOfRangeError, Parameter: "compensation", Reason: "could not be worked out for this string; check its action, gauge and tension"}
+	}
+	compensation := &Compensation{
+		NutOffset:    roundToPrecisionOf(nutOffset/metres, units),
+		SaddleOffset: roundToPrecisionOf(saddleOffset/metres, units),
+	}
+	for i, x := range positions {
+		compensation.Frets = append(compensation.Frets, CompensatedFret{
+			Fret:               i + 1,
+			UncompensatedError: roundToHundredths(model.pitchError(x, 0, 0)),
+			Error:              roundToHundredths(model.pitchError(x, nutOffset, saddleOffset)),
+		})
+	}
+	return compensation, nil
+}
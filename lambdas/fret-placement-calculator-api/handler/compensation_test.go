@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_idealStringShouldPlayInTuneWithoutCompensation(t *testing.T) {
+	// Given
+	model := fretStringModel{scaleLength: 0.65, diameter: 0.001, tension: 60, material: stringMaterial{youngsModulus: 0, coreRatio: 1}}
+
+	// When
+	// Then
+	for _, x := range []float64{0.0365, 0.1625, 0.325, 0.4875} {
+		assert.InDelta(t, 0, model.pitchError(x, 0, 0), 1e-9)
+	}
+}
+
+func Test_frettedStringsShouldGoSharpAndBeBroughtBackByCompensation(t *testing.T) {
+	// Given
+	model := fretStringModel{scaleLength: 0.648, nutHeight: 0.0005, saddleHeight: 0.0035, diameter: 0.000254, tension: 72, material: lookupStringMaterial("plainSteel")}
+	var positions []float64
+	for fret := 1; fret <= 24; fret++ {
+		positions = append(positions, 0.648*(1-math.Pow(2, -float64(fret)/12)))
+	}
+
+	// When
+	nutOffset, saddleOffset := model.compensate(positions)
+
+	// Then
+	assert.Greater(t, saddleOffset, 0.0)
+	assert.Greater(t, nutOffset, 0.0)
+	previous := 0.0
+	for _, x := range positions {
+		uncompensated := model.pitchError(x, 0, 0)
+		assert.Greater(t, uncompensated, previous)
+		assert.Less(t, math.Abs(model.pitchError(x, nutOffset, saddleOffset)), 0.5)
+		previous = uncompensated
+	}
+}
+
+func Test_ShouldReturnCompensatedNutAndSaddleOffsets(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "equal", "divisions": "12", "nutAction": "0.5", "twelfthFretAction": "2", "gauge": "0.254", "tension": "72"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 13, len(fretboard.Frets))
+	assert.Equal(t, 0.31, fretboard.Compensation.NutOffset)
+	assert.Equal(t, 1.97, fretboard.Compensation.SaddleOffset)
+	assert.Equal(t, 12, len(fretboard.Compensation.Frets))
+	assert.Equal(t, CompensatedFret{Fret: 1, UncompensatedError: 1.45, Error: 0.29}, fretboard.Compensation.Frets[0])
+}
+
+func Test_ShouldCompensateScaleLengthsGivenInInches(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "25.5118", "units": "in", "tuningSystem": "equal", "divisions": "12", "nutAction": "0.019685", "twelfthFretAction": "0.07874", "gauge": "0.01", "tension": "72"},
+	})
+
+	// Then
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 0.078, fretboard.Compensation.SaddleOffset)
+	assert.Equal(t, 1.45, fretboard.Compensation.Frets[0].UncompensatedError)
+}
+
+func Test_ShouldCompensateOnlyTheFretsWithinReach(t *testing.T) {
+	for _, octaves := range []string{"8", "32"} {
+		t.Run(octaves+" octaves", func(t *testing.T) {
+			// Given
+			// When
+			response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+				QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "equal", "divisions": "12", "octaves": octaves, "nutAction": "0.5", "twelfthFretAction": "2", "gauge": "0.254", "tension": "72"},
+			})
+
+			// Then
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			var fretboard DetailedFretboard
+			assert.Nil(t, json.Unmarshal([]byte(response.Body), &fretboard))
+			assert.Equal(t, 24, len(fretboard.Compensation.Frets))
+			for _, fret := range fretboard.Compensation.Frets {
+				assert.Less(t, math.Abs(fret.Error), 0.5)
+			}
+		})
+	}
+}
+
+func Test_ShouldLeaveFretboardsUncompensatedUnlessAsked(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "saz"},
+	})
+
+	// Then
+	assert.NotContains(t, response.Body, "compensation")
+}
+
+func Test_ShouldReturnErrorIfCompensationParametersAreInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "incomplete",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "saz", "gauge": "0.254", "material": "brass"},
+			body:  `{"errors":[{"code":"required","parameter":"nutAction","reason":"is required"},{"code":"required","parameter":"twelfthFretAction","reason":"is required"},{"code":"not_allowed","parameter":"material","reason":"must be one of the allowed values","allowedValues":["plainSteel","nickelWound","phosphorBronzeWound","nylon","gut"]},{"code":"required","parameter":"tension","reason":"is required"}]}`,
+		},
+		{
+			name:  "string lying on the frets",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "saz", "nutAction": "1", "twelfthFretAction": "0.5", "gauge": "0.254", "tension": "72"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"twelfthFretAction","reason":"must be more than half of nutAction, or the string would lie on the frets"}]}`,
+		},
+		{
+			name:  "no frets within reach",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "equal", "divisions": "1", "interval": "5:1", "nutAction": "0.5", "twelfthFretAction": "2", "gauge": "0.254", "tension": "72"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"tuningSystem","reason":"must have frets within three quarters of the scale length to be compensated"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldDescribeEveryTuningSystemAndItsParameters(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{RawPath: "/tuningSystems"})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, headers, response.Headers)
+
+	var description struct {
+		Parameters    []map[string]any `json:"parameters"`
+		TuningSystems []map[string]any `json:"tuningSystems"`
+	}
+	_ = json.Unmarshal([]byte(response.Body), &description)
+	assert.Equal(t, len(tuningSystems.All()), len(description.TuningSystems))
+	assert.Equal(t, map[string]any{"name": "octaves", "type": "integer", "description": "Number of periods of frets to compute: octaves, unless the tuning system repeats at another interval", "default": 1.0, "minimum": 1.0, "maximum": 32.0}, description.Parameters[2])
+	assert.Contains(t, description.Parameters[1]["allowedValues"], "bachWellTemperament")
+
+	var equal map[string]any
+	for _, system := range description.TuningSystems {
+		if system["id"] == "equal" {
+			equal = system
+		}
+	}
+	assert.Equal(t, "Equal Temperament", equal["name"])
+	assert.Equal(t, map[string]any{"name": "divisions", "type": "integer", "description": "Number of divisions of the interval", "default": 31.0, "minimum": 1.0, "maximum": 1200.0}, equal["parameters"].([]any)[0])
+	assert.Equal(t, map[string]any{"name": "interval", "type": "interval", "description": "Interval divided into equal steps, as a ratio (3:1 for Bohlen-Pierce) or in cents (1203 for a stretched octave)", "default": "2:1"}, equal["parameters"].([]any)[1])
+}
+
+func Test_ShouldDescribeAllowedValuesOfStringParameters(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{RawPath: "/tuningSystems/"})
+
+	// Then
+	var description TuningSystemsDescription
+	_ = json.Unmarshal([]byte(response.Body), &description)
+	ptolemy, _ := tuningSystems.Lookup("ptolemy")
+	for _, system := range description.TuningSystems {
+		if system.ID == "ptolemy" {
+			assert.Equal(t, ptolemy.Parameters, system.Parameters)
+			assert.Equal(t, 7, len(system.Parameters[0].AllowedValues))
+		}
+	}
+}
+
+func Test_ShouldDescribeDefaultIntervalsAsRatios(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{RawPath: "/tuningSystems"})
+
+	// Then
+	assert.Contains(t, response.Body, `{"name":"period","type":"interval","description":"Interval at which the scale repeats, as a ratio or in cents","default":"2:1"}`)
+}
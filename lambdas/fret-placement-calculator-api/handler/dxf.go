@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikebharris/music/instruments"
+)
+
+const (
+	dxfFretsLayer      = "FRETS"
+	dxfNutLayer        = "NUT"
+	dxfBridgeLayer     = "BRIDGE"
+	dxfCentreLineLayer = "CENTRELINE"
+	dxfOutlineLayer    = "OUTLINE"
+)
+
+// renderDXF produces an AutoCAD R12 DXF drawing with the nut at the origin, the centre line along the x-axis, and
+// each fret as a line across the (optionally tapered) fretboard.
+func renderDXF(fretboard instruments.Fretboard, taper taper) string {
+	var b strings.Builder
+	writeDXFHeader(&b)
+
+	b.WriteString("0\nSECTION\n2\nENTITIES\n")
+	writeDXFLine(&b, dxfCentreLineLayer, 0, 0, fretboard.ScaleLength, 0)
+	writeDXFLine(&b, dxfNutLayer, 0, -taper.nutWidth/2, 0, taper.nutWidth/2)
+	for _, fret := range fretboard.Frets {
+		if fret.Position == 0 {
+			continue
+		}
+		width := taper.widthAt(fret.Position)
+		writeDXFLine(&b, dxfFretsLayer, fret.Position, -width/2, fret.Position, width/2)
+	}
+	writeDXFLine(&b, dxfOutlineLayer, 0, -taper.nutWidth/2, taper.length, -taper.heelWidth/2)
+	writeDXFLine(&b, dxfOutlineLayer, 0, taper.nutWidth/2, taper.length, taper.heelWidth/2)
+	bridgeWidth := taper.widthAt(fretboard.ScaleLength)
+	writeDXFLine(&b, dxfBridgeLayer, fretboard.ScaleLength, -bridgeWidth/2, fretboard.ScaleLength, bridgeWidth/2)
+	b.WriteString("0\nENDSEC\n0\nEOF\n")
+	return b.String()
+}
+
+// writeDXFHeader declares the drawing as R12, which has no header variable for its units, so the drawing is in those of
+// the scale length.
+func writeDXFHeader(b *strings.Builder) {
+	b.WriteString("0\nSECTION\n2\nHEADER\n9\n$ACADVER\n1\nAC1009\n0\nENDSEC\n")
+
+	layers := []string{dxfFretsLayer, dxfNutLayer, dxfBridgeLayer, dxfCentreLineLayer, dxfOutlineLayer}
+	fmt.Fprintf(b, "0\nSECTION\n2\nTABLES\n0\nTABLE\n2\nLAYER\n70\n%d\n", len(layers))
+	for i, layer := range layers {
+		fmt.Fprintf(b, "0\nLAYER\n2\n%s\n70\n0\n62\n%d\n6\nCONTINUOUS\n", layer, i+1)
+	}
+	b.WriteString("0\nENDTAB\n0\nENDSEC\n")
+}
+
+func writeDXFLine(b *strings.Builder, layer string, x1, y1, x2, y2 float64) {
+	fmt.Fprintf(b, "0\nLINE\n8\n%s\n10\n%s\n20\n%s\n30\n0\n11\n%s\n21\n%s\n31\n0\n", layer, formatFloat(x1), formatFloat(y1), formatFloat(x2), formatFloat(y2))
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnDXFDrawingWithFretsNutBridgeAndCentreLineOnSeparateLayers(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "12", "format": "dxf"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/dxf", response.Headers["Content-Type"])
+	assert.True(t, strings.HasPrefix(response.Body, "0\nSECTION\n2\nHEADER\n9\n$ACADVER\n1\nAC1009\n0\nENDSEC\n"))
+	assert.True(t, strings.HasSuffix(response.Body, "0\nENDSEC\n0\nEOF\n"))
+	assert.Equal(t, 12, strings.Count(response.Body, "LINE\n8\nFRETS\n"))
+	assert.Contains(t, response.Body, "0\nLINE\n8\nCENTRELINE\n10\n0\n20\n0\n30\n0\n11\n600\n21\n0\n31\n0\n")
+	assert.Contains(t, response.Body, "0\nLINE\n8\nNUT\n10\n0\n20\n-25\n30\n0\n11\n0\n21\n25\n31\n0\n")
+	assert.Contains(t, response.Body, "0\nLINE\n8\nFRETS\n10\n33.68\n20\n-25\n30\n0\n11\n33.68\n21\n25\n31\n0\n")
+	assert.Contains(t, response.Body, "0\nLINE\n8\nBRIDGE\n10\n600\n20\n-25\n30\n0\n11\n600\n21\n25\n31\n0\n")
+}
+
+func Test_ShouldReturnDXFDrawingInCentimetres(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "60", "units": "cm", "tuningSystem": "equal", "divisions": "12", "format": "dxf"},
+	})
+
+	// Then
+	assert.Contains(t, response.Body, "0\nLINE\n8\nNUT\n10\n0\n20\n-2.5\n30\n0\n11\n0\n21\n2.5\n31\n0\n")
+}
+
+func Test_ShouldTaperDXFFretboardFromNutToHeel(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "12", "format": "dxf", "fretboardNutWidth": "42", "fretboardHeelWidth": "56"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Body, "0\nLINE\n8\nNUT\n10\n0\n20\n-21\n30\n0\n11\n0\n21\n21\n31\n0\n")
+	assert.Contains(t, response.Body, "0\nLINE\n8\nFRETS\n10\n300\n20\n-28\n30\n0\n11\n300\n21\n28\n31\n0\n")
+	assert.Contains(t, response.Body, "0\nLINE\n8\nOUTLINE\n10\n0\n20\n21\n30\n0\n11\n300\n21\n28\n31\n0\n")
+	assert.Contains(t, response.Body, "0\nLINE\n8\nBRIDGE\n10\n600\n20\n-35\n30\n0\n11\n600\n21\n35\n31\n0\n")
+}
+
+func Test_ShouldReturnErrorWhenDXFTaperIsInvalid(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "saz", "format": "dxf", "fretboardHeelWidth": "wide"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"invalid_type","parameter":"fretboardHeelWidth","reason":"must be a number"}]}`}, response)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/mikebharris/music/music"
+)
+
+var equalParameters = []Parameter{
+	{Name: "divisions", Type: IntegerParameter, Description: "Number of divisions of the interval", Default: defaultEqualTemperamentDivisions, Minimum: bound(1), Maximum: bound(1200)},
+	{Name: "interval", Type: IntervalParameter, Description: "Interval divided into equal steps, as a ratio (3:1 for Bohlen-Pierce) or in cents (1203 for a stretched octave)", Default: scaleInterval{ratio: music.Octave(), just: true}},
+	{Name: "stepSize", Type: NumberParameter, Description: "Size in cents of every step, in place of divisions and interval, for scales that divide no interval such as 88-cent equal temperament", ExclusiveMinimum: bound(0), Maximum: bound(maximumInterval)},
+}
+
+// newEqualScale divides the octave as the music module does, and any other interval, or none at all when given
+// the size of each step, into equal steps in cents.
+func newEqualScale(periods int, args arguments) fretScale {
+	if args.has("stepSize") {
+		step := scaleInterval{cents: args.number("stepSize")}
+		description := fmt.Sprintf("Fret positions based on equal steps of %s cents.", step)
+		return newScaleFromIntervals("Equal Temperament", description, periods, nil, step)
+	}
+
+	divisions, interval := args.integer("divisions"), args.interval("interval")
+	if interval.just && interval.ratio == music.Octave() {
+		return newTemperedScale(periods, music.NewEqualTemperamentScale(uint(divisions)))
+	}
+	var steps []scaleInterval
+	for i := 1; i < divisions; i++ {
+		steps = append(steps, scaleInterval{cents: interval.toCents() * float64(i) / float64(divisions)})
+	}
+	description := fmt.Sprintf("Fret positions based on %d equal divisions of %s.", divisions, interval)
+	return newScaleFromIntervals("Equal Temperament", description, periods, steps, interval)
+}
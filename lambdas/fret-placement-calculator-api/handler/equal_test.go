@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+	"github.com/stretchr/testify/assert"
+)
+
+func equalFretboard(q map[string]string) DetailedFretboard {
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	return fretboard
+}
+
+func Test_ShouldDivideTheTritaveForBohlenPierce(t *testing.T) {
+	// Given
+	// When
+	fretboard := equalFretboard(map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "13", "interval": "3:1"})
+
+	// Then
+	assert.Equal(t, "Fret positions based on 13 equal divisions of 3:1.", fretboard.Description)
+	assert.Equal(t, 14, len(fretboard.Frets))
+	assert.Equal(t, instruments.Fret{Label: "146.30 cents", Position: 48.62}, fretboard.Frets[1])
+	assert.Equal(t, instruments.Fret{Label: "3:1", Position: 400}, fretboard.Frets[13])
+}
+
+func Test_ShouldDivideAStretchedOctave(t *testing.T) {
+	// Given
+	// When
+	fretboard := equalFretboard(map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "12", "interval": "1203", "octaves": "2"})
+
+	// Then
+	assert.Equal(t, "Fret positions based on 12 equal divisions of 1203.", fretboard.Description)
+	assert.Equal(t, 25, len(fretboard.Frets))
+	assert.Equal(t, "100.25 cents", fretboard.Frets[1].Label)
+	assert.Equal(t, "2406.00 cents", fretboard.Frets[24].Label)
+}
+
+func Test_ShouldRepeatEqualStepsOfAGivenSizeForEveryPeriod(t *testing.T) {
+	// Given
+	// When
+	fretboard := equalFretboard(map[string]string{"scaleLength": "600", "tuningSystem": "equal", "stepSize": "88", "octaves": "3"})
+
+	// Then
+	assert.Equal(t, "Fret positions based on equal steps of 88 cents.", fretboard.Description)
+	assert.Equal(t, []instruments.Fret{
+		{Label: "0.00 cents", Position: 0},
+		{Label: "88.00 cents", Position: 29.74},
+		{Label: "176.00 cents", Position: 58},
+		{Label: "264.00 cents", Position: 84.86},
+	}, fretboard.Frets)
+}
+
+func Test_ShouldComputeFretsUpToTheMaximumPosition(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		frets int
+	}{
+		{name: "octave", query: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "12", "maximumPosition": "400"}, frets: 20},
+		{name: "equal steps", query: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "stepSize": "88", "maximumPosition": "300"}, frets: 14},
+		{name: "more periods than octaves allows", query: map[string]string{"scaleLength": "650", "tuningSystem": "equal", "stepSize": "10", "maximumPosition": "300"}, frets: 108},
+		{name: "fret on the maximum", query: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "13", "interval": "3:1", "maximumPosition": "400"}, frets: 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			tt.query["openString"] = "E2"
+
+			// When
+			fretboard := equalFretboard(tt.query)
+
+			// Then
+			assert.Equal(t, tt.frets, len(fretboard.Frets))
+			assert.Equal(t, tt.frets, len(fretboard.Pitches))
+		})
+	}
+}
+
+func Test_ShouldReturnErrorIfEqualStepsAreInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "unison interval",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "interval": "1:1"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"interval","reason":"must be larger than 1:1"}]}`,
+		},
+		{
+			name:  "step of no size",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "stepSize": "0"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"stepSize","reason":"must be greater than 0"}]}`,
+		},
+		{
+			name:  "interval reaching the bridge",
+			query: map[string]string{"scaleLength": "650", "tuningSystem": "equal", "interval": "100000", "divisions": "2", "openString": "E2"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"interval","reason":"must be no larger than 4800 cents (16:1)"}]}`,
+		},
+		{
+			name:  "step reaching the bridge",
+			query: map[string]string{"scaleLength": "650", "tuningSystem": "equal", "stepSize": "5000"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"stepSize","reason":"must be at most 4800"}]}`,
+		},
+		{
+			name:  "maximum position beyond the bridge",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "maximumPosition": "600"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"maximumPosition","reason":"must be less than the scaleLength"}]}`,
+		},
+		{
+			name:  "too many frets to the maximum position",
+			query: map[string]string{"scaleLength": "650", "tuningSystem": "equal", "stepSize": "0.01", "maximumPosition": "600"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"maximumPosition","reason":"must be reached within 10000 frets"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
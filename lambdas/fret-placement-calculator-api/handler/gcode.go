@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikebharris/music/instruments"
+)
+
+type gcodeSettings struct {
+	slotDepth    float64
+	feedRate     float64
+	safeZ        float64
+	originX      float64
+	originY      float64
+	spindleSpeed float64
+}
+
+var gcodeParameters = []Parameter{
+	{Name: "slotDepth", Type: NumberParameter, Description: "Depth of each slot below Z0", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "feedRate", Type: NumberParameter, Description: "Feed rate for plunging and cutting", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "safeZ", Type: NumberParameter, Description: "Height to retract to between slots (defaults to 5mm, 0.5cm or 0.2in)", ExclusiveMinimum: bound(0)},
+	{Name: "spindleSpeed", Type: NumberParameter, Description: "Spindle speed; when given the spindle is started and stopped", ExclusiveMinimum: bound(0)},
+	{Name: "originX", Type: NumberParameter, Description: "Machine X coordinate of the nut", Default: 0.0},
+	{Name: "originY", Type: NumberParameter, Description: "Machine Y coordinate of the centre line", Default: 0.0},
+}
+
+func newGCodeSettings(args arguments, units string) gcodeSettings {
+	safeZ := 5.0
+	switch units {
+	case "in":
+		safeZ = 0.2
+	case "cm":
+		safeZ = 0.5
+	}
+	return gcodeSettings{
+		slotDepth:    args.number("slotDepth"),
+		feedRate:     args.number("feedRate"),
+		safeZ:        args.numberOr("safeZ", safeZ),
+		originX:      args.number("originX"),
+		originY:      args.number("originY"),
+		spindleSpeed: args.numberOr("spindleSpeed", 0),
+	}
+}
+
+// renderGCode produces a program that cuts each fret slot across the fretboard, alternating direction to save
+// travel.  The machine origin is the point where the centre line crosses the nut, X runs towards the bridge and
+// Y runs across the fretboard.  G-code has no centimetres, so those are given in millimetres.
+func renderGCode(fretboard instruments.Fretboard, units string, taper taper, settings gcodeSettings) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "(%s, scale length %s%s)\n", fretboard.System, formatFloat(fretboard.ScaleLength), units)
+	length := formatFloat
+	if units == "in" {
+		b.WriteString("G20\n")
+	} else {
+		b.WriteString("G21\n")
+	}
+	if units == "cm" {
+		length = func(f float64) string { return formatFloat(f * 10) }
+	}
+	b.WriteString("G90\nG17\n")
+	fmt.Fprintf(&b, "G0 Z%s\n", length(settings.safeZ))
+	if settings.spindleSpeed > 0 {
+		fmt.Fprintf(&b, "M3 S%s\n", formatFloat(settings.spindleSpeed))
+	}
+
+	direction := 1.0
+	for i, fret := range fretboard.Frets {
+		if fret.Position == 0 {
+			continue
+		}
+		x := settings.originX + fret.Position
+		halfWidth := direction * taper.widthAt(fret.Position) / 2
+		fmt.Fprintf(&b, "(Fret %d: %s)\n", i, fret.Label)
+		fmt.Fprintf(&b, "G0 X%s Y%s\n", length(x), length(settings.originY-halfWidth))
+		fmt.Fprintf(&b, "G1 Z%s F%s\n", length(-settings.slotDepth), length(settings.feedRate))
+		fmt.Fprintf(&b, "G1 Y%s\n", length(settings.originY+halfWidth))
+		fmt.Fprintf(&b, "G0 Z%s\n", length(settings.safeZ))
+		direction = -direction
+	}
+
+	if settings.spindleSpeed > 0 {
+		b.WriteString("M5\n")
+	}
+	fmt.Fprintf(&b, "G0 X%s Y%s\n", length(settings.originX), length(settings.originY))
+	b.WriteString("M30\n")
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnGCodeThatCutsEachFretSlot(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "540", "tuningSystem": "ptolemy", "format": "gcode", "slotDepth": "3", "feedRate": "250", "fretboardWidth": "60", "originX": "10", "originY": "100", "spindleSpeed": "12000"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/x-gcode", response.Headers["Content-Type"])
+	assert.True(t, strings.HasPrefix(response.Body, "(Ptolemy Intense Diatonic, scale length 540mm)\nG21\nG90\nG17\nG0 Z5\nM3 S12000\n"))
+	assert.Contains(t, response.Body, "(Fret 1: 9:8)\nG0 X70 Y70\nG1 Z-3 F250\nG1 Y130\nG0 Z5\n")
+	assert.Contains(t, response.Body, "(Fret 2: 5:4)\nG0 X118 Y130\nG1 Z-3 F250\nG1 Y70\nG0 Z5\n")
+	assert.Contains(t, response.Body, "(Fret 7: 2:1)\nG0 X280 Y70\n")
+	assert.True(t, strings.HasSuffix(response.Body, "M5\nG0 X10 Y100\nM30\n"))
+	assert.Equal(t, 7, strings.Count(response.Body, "G1 Z-3"))
+}
+
+func Test_ShouldReturnGCodeInInchesFollowingTheTaper(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "24", "tuningSystem": "equal", "divisions": "12", "format": "gcode", "units": "in", "slotDepth": "0.1", "feedRate": "10", "fretboardNutWidth": "1.5", "fretboardHeelWidth": "2.5"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, strings.HasPrefix(response.Body, "(Equal Temperament, scale length 24in)\nG20\nG90\nG17\nG0 Z0.2\n(Fret 1"))
+	assert.Contains(t, response.Body, "(Fret 12: 1200.00 cents)\nG0 X12 Y1.25\nG1 Z-0.1 F10\nG1 Y-1.25\nG0 Z0.2\n")
+	assert.NotContains(t, response.Body, "M3 S")
+	assert.NotContains(t, response.Body, "M5")
+}
+
+func Test_ShouldReturnGCodeInMillimetresForCentimetres(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "54", "units": "cm", "tuningSystem": "ptolemy", "format": "gcode", "slotDepth": "0.3", "feedRate": "25", "fretboardWidth": "6"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(response.Body, "(Ptolemy Intense Diatonic, scale length 54cm)\nG21\nG90\nG17\nG0 Z5\n"))
+	assert.Contains(t, response.Body, "(Fret 1: 9:8)\nG0 X60 Y-30\nG1 Z-3 F250\nG1 Y30\nG0 Z5\n")
+	assert.True(t, strings.HasSuffix(response.Body, "G0 X0 Y0\nM30\n"))
+}
+
+func Test_ShouldReturnErrorWhenGCodeSettingsAreInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "missing slot depth",
+			query: map[string]string{"scaleLength": "540", "tuningSystem": "saz", "format": "gcode", "feedRate": "250"},
+			body:  `{"errors":[{"code":"required","parameter":"slotDepth","reason":"is required"}]}`,
+		},
+		{
+			name:  "missing feed rate",
+			query: map[string]string{"scaleLength": "540", "tuningSystem": "saz", "format": "gcode", "slotDepth": "3"},
+			body:  `{"errors":[{"code":"required","parameter":"feedRate","reason":"is required"}]}`,
+		},
+		{
+			name:  "non-numeric origin",
+			query: map[string]string{"scaleLength": "540", "tuningSystem": "saz", "format": "gcode", "slotDepth": "3", "feedRate": "250", "originX": "left"},
+			body:  `{"errors":[{"code":"invalid_type","parameter":"originX","reason":"must be a number"}]}`,
+		},
+		{
+			name:  "invalid fretboard width",
+			query: map[string]string{"scaleLength": "540", "tuningSystem": "saz", "format": "gcode", "slotDepth": "3", "feedRate": "250", "fretboardWidth": "0"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"fretboardWidth","reason":"must be greater than 0"}]}`,
+		},
+		{
+			name:  "everything missing",
+			query: map[string]string{"scaleLength": "540", "tuningSystem": "saz", "format": "gcode", "safeZ": "-1"},
+			body:  `{"errors":[{"code":"required","parameter":"slotDepth","reason":"is required"},{"code":"required","parameter":"feedRate","reason":"is required"},{"code":"out_of_range","parameter":"safeZ","reason":"must be greater than 0"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
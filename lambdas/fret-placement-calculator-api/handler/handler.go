@@ -2,19 +2,28 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
 	"net/http"
-	"strconv"
+	"slices"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/mikebharris/music/instruments"
-	"github.com/mikebharris/music/music"
 )
 
 const (
 	defaultEqualTemperamentDivisions = 31
 	defaultJustLimit                 = 5
 	defaultNumberOfOctaves           = 1
+	maximumPeriods                   = 32
+	maximumFrets                     = 10000
+	// maximumInterval is the largest interval in cents, of four octaves, that a scale may span before it repeats, as
+	// frets much further up would crowd onto the bridge.
+	maximumInterval = 4800
 )
 
 var headers = map[string]string{
@@ -25,62 +34,222 @@ type Handler struct {
 }
 
 func (h Handler) HandleRequest(_ context.Context, request events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
-	q := request.QueryStringParameters
-
-	scaleLength, err := strconv.ParseFloat(q["scaleLength"], 64)
-	if err != nil || scaleLength <= 0 {
-		return errorResponse(http.StatusUnprocessableEntity, `{"error":"a numeric scaleLength greater than zero is required"}`), nil
-	}
-
-	var fretboard instruments.Fretboard
-	octaves := parseIntegerQueryParameter(q, "octaves", defaultNumberOfOctaves)
-
-	switch q["tuningSystem"] {
-	case "equal":
-		fretboard = instruments.NewFretboardFromTemperedScale(scaleLength, octaves, music.NewEqualTemperamentScale(uint(parseIntegerQueryParameter(q, "divisions", defaultEqualTemperamentDivisions))))
-	case "saz":
-		fretboard = instruments.NewFretboardFromJustScale(scaleLength, octaves, music.NewSazScale())
-	case "pythagorean":
-		fretboard = instruments.NewFretboardFromJustScale(scaleLength, octaves, music.NewPythagoreanScale())
-	case "meantone":
-		fretboard = instruments.NewFretboardFromTemperedScale(scaleLength, octaves, music.NewQuarterCommaMeantoneScale())
-	case "extendedMeantone":
-		fretboard = instruments.NewFretboardFromTemperedScale(scaleLength, octaves, music.NewExtendedQuarterCommaMeantoneScale())
-	case "ptolemy":
-		fretboard = instruments.NewFretboardFromJustScale(scaleLength, octaves, music.NewIntenseDiatonicScale(music.MusicalMode(validDiatonicModeOrDefault(q["diatonicMode"]))))
-	case "just5limitFromPythagorean":
-		fretboard = instruments.NewFretboardFromJustScale(scaleLength, octaves, music.New5LimitPythagoreanScale())
-	case "justFromRatios":
-		fretboard = instruments.NewFretboardFromJustScale(scaleLength, octaves, music.NewJustIntonationChromaticScaleWithLimit(parseIntegerQueryParameter(q, "limit", defaultJustLimit)))
-	case "bachWellTemperament":
-		fretboard = instruments.NewFretboardFromTemperedScale(scaleLength, octaves, music.NewBachWohltemperierteKlavierScale())
-	default:
-		return errorResponse(http.StatusUnprocessableEntity, `{"error":"please provide a valid tuning system"}`), nil
+	switch strings.TrimSuffix(request.RawPath, "/") {
+	case "/tuningSystems":
+		return h.handleTuningSystemsRequest(), nil
+	case "/batch":
+		return h.handleBatchRequest(request), nil
+	}
+
+	q, errors := parametersFrom(request)
+	if len(errors) > 0 {
+		return validationErrorResponse(errors...), nil
 	}
 
-	fretboard.ScaleLength = scaleLength
-	body, _ := json.Marshal(fretboard)
-	return events.LambdaFunctionURLResponse{StatusCode: http.StatusOK, Headers: headers, Body: string(body)}, nil
+	switch strings.TrimSuffix(request.RawPath, "/") {
+	case "/compare":
+		return h.handleCompareRequest(q), nil
+	case "/tension":
+		return h.handleTensionRequest(q), nil
+	case "/noteMap":
+		return h.handleNoteMapRequest(q), nil
+	case "/perStringFrets":
+		return h.handlePerStringRequest(q), nil
+	}
+	return h.calculate(q, request.Headers["accept"]), nil
 }
 
-func validDiatonicModeOrDefault(mode string) string {
-	if mode == "" || !music.MusicalMode(mode).IsDiatonic() {
-		mode = music.IonianMode.String()
+// calculate works out the fretboard described by the parameters q, in the format they or the accept header ask for.
+func (h Handler) calculate(q map[string]string, accept string) events.LambdaFunctionURLResponse {
+	if isMultiscaleRequest(q) {
+		return h.handleMultiscaleRequest(q)
+	}
+
+	v := newValidator(q)
+	v.q["format"] = responseFormat(q, accept)
+	v.parse(commonParameters()...)
+	v.checkMaximumPosition()
+	system, _ := v.parseTuningSystem()
+	if v.args.has("format") {
+		v.parse(formatParameters[v.args.text("format")]...)
+		v.checkFractionUnits()
 	}
-	return mode
+	if isCompensationRequest(v.q) {
+		v.parseCompensation()
+	}
+	if v.q["openString"] != "" {
+		v.parse(pitchParameters...)
+	}
+	if !v.valid() {
+		return v.errorResponse()
+	}
+
+	octaves := v.args.integer("octaves")
+	if format := v.args.text("format"); format == "scl" || format == "kbm" {
+		octaves = 1 // Scala describes a single period of the scale
+		delete(v.args, "maximumPosition")
+	}
+	return fretboardResponse(v.args, system.scale(octaves, v.args))
 }
 
-func errorResponse(status int, body string) events.LambdaFunctionURLResponse {
-	return events.LambdaFunctionURLResponse{StatusCode: status, Headers: headers, Body: body}
+// parametersFrom gathers the parameters of a request: those in the query string and, when POSTed, either those of a
+// JSON body or a Scala file sent as plain text or a form upload, which is assumed to be what should be fretted unless
+// another tuning system is asked for.  Bodies of any other content type are refused.
+func parametersFrom(request events.LambdaFunctionURLRequest) (map[string]string, []ValidationError) {
+	q := maps.Clone(request.QueryStringParameters)
+	if q == nil {
+		q = map[string]string{}
+	}
+	if request.RequestContext.HTTP.Method != http.MethodPost {
+		return q, nil
+	}
+	if isJSONRequest(request) {
+		return jsonParametersFrom(request, q)
+	}
+	if !isScalaRequest(request) {
+		mediaType, _, _ := mime.ParseMediaType(request.Headers["content-type"])
+		return nil, []ValidationError{{Code: InvalidTypeError, Parameter: "body", Reason: fmt.Sprintf("must be application/json, text/plain or multipart/form-data, not %s", mediaType)}}
+	}
+
+	scl, err := scalaFileFrom(request)
+	if err != nil {
+		return nil, []ValidationError{{Code: InvalidTypeError, Parameter: "scl", Reason: "must be a Scala scale file sent as the request body or as a multipart form upload"}}
+	}
+	q["scl"] = scl
+	if q["tuningSystem"] == "" {
+		q["tuningSystem"] = "scala"
+	}
+	return q, nil
 }
 
-func parseIntegerQueryParameter(q map[string]string, key string, fallback int) int {
-	if q[key] == "" {
-		return fallback
+type TuningSystemsDescription struct {
+	Parameters             []Parameter            `json:"parameters"`
+	FormatParameters       map[string][]Parameter `json:"formatParameters"`
+	CompensationParameters []Parameter            `json:"compensationParameters"`
+	PitchParameters        []Parameter            `json:"pitchParameters"`
+	MultiscaleParameters   []Parameter            `json:"multiscaleParameters"`
+	CompareParameters      []Parameter            `json:"compareParameters"`
+	TensionParameters      []Parameter            `json:"tensionParameters"`
+	NoteMapParameters      []Parameter            `json:"noteMapParameters"`
+	PerStringParameters    []Parameter            `json:"perStringParameters"`
+	TuningSystems          []TuningSystem         `json:"tuningSystems"`
+}
+
+func (h Handler) handleTuningSystemsRequest() events.LambdaFunctionURLResponse {
+	return jsonResponse(TuningSystemsDescription{
+		Parameters:             commonParameters(),
+		FormatParameters:       formatParameters,
+		CompensationParameters: compensationParameters,
+		PitchParameters:        pitchParameters,
+		MultiscaleParameters:   multiscaleParameters,
+		CompareParameters:      compareParameters,
+		TensionParameters:      tensionParameters,
+		NoteMapParameters:      noteMapParameters,
+		PerStringParameters:    perStringParameters,
+		TuningSystems:          tuningSystems.All(),
+	})
+}
+
+// formatParameters are those accepted in addition to the common parameters when a particular format is requested.
+var formatParameters = map[string][]Parameter{
+	"json":  fractionParameters,
+	"csv":   fractionParameters,
+	"tsv":   fractionParameters,
+	"dxf":   taperParameters,
+	"gcode": slices.Concat(taperParameters, gcodeParameters),
+	"pdf":   slices.Concat(taperParameters, pdfParameters),
+	"kbm":   kbmParameters,
+}
+
+func formats() []string {
+	return []string{"json", "csv", "tsv", "svg", "dxf", "gcode", "pdf", "scl", "kbm"}
+}
+
+func fretboardResponse(args arguments, scale fretScale) events.LambdaFunctionURLResponse {
+	units := args.text("units")
+	fretboard := scale.fretboard(args.number("scaleLength"), units)
+	switch args.text("format") {
+	case "csv":
+		return textResponse("text/csv", renderDelimitedTable(fretboard, ',', units, fractionalPositionsIfAsked(args, scale)))
+	case "tsv":
+		return textResponse("text/tab-separated-values", renderDelimitedTable(fretboard, '\t', units, fractionalPositionsIfAsked(args, scale)))
+	case "svg":
+		return textResponse("image/svg+xml", renderSVG(fretboard, units))
+	case "dxf":
+		return textResponse("application/dxf", renderDXF(fretboard, newTaper(args, fretboard, units)))
+	case "gcode":
+		return textResponse("text/x-gcode", renderGCode(fretboard, units, newTaper(args, fretboard, units), newGCodeSettings(args, units)))
+	case "pdf":
+		return binaryResponse("application/pdf", renderPDF(fretboard, units, newTaper(args, fretboard, units), args.text("paper")))
+	case "scl":
+		return attachmentResponse("text/plain", args.text("tuningSystem")+".scl", renderSCL(fretboard, args.text("tuningSystem")))
+	case "kbm":
+		return attachmentResponse("text/plain", args.text("tuningSystem")+".kbm", renderKBM(fretboard, args.text("tuningSystem"), args))
+	default:
+		detailed, err := newDetailedFretboard(args, scale, fretboard)
+		if err != nil {
+			return validationErrorResponse(*err)
+		}
+		return jsonResponse(detailed)
+	}
+}
+
+// DetailedFretboard is a fretboard with whatever further detail was asked for.
+type DetailedFretboard struct {
+	instruments.Fretboard
+	Units               string               `json:"units"`
+	FractionalPositions []FractionalPosition `json:"fractionalPositions,omitempty"`
+	Compensation        *Compensation        `json:"compensation,omitempty"`
+	Pitches             []FretPitch          `json:"pitches,omitempty"`
+}
+
+func newDetailedFretboard(args arguments, scale fretScale, fretboard instruments.Fretboard) (DetailedFretboard, *ValidationError) {
+	detailed := DetailedFretboard{Fretboard: fretboard, Units: args.text("units")}
+	detailed.FractionalPositions = fractionalPositionsIfAsked(args, scale)
+	if args.has("openString") {
+		detailed.Pitches = fretboardPitches(args, scale)
+	}
+	if args.has("gauge") {
+		compensation, err := newCompensation(args, fretboard, args.text("units"))
+		if err != nil {
+			return DetailedFretboard{}, err
+		}
+		detailed.Compensation = compensation
+	}
+	return detailed, nil
+}
+
+func responseFormat(q map[string]string, accept string) string {
+	if format := q["format"]; format != "" {
+		return format
+	}
+	if strings.Contains(accept, "image/svg+xml") {
+		return "svg"
 	}
-	atoi, err := strconv.Atoi(q[key])
-	if err != nil || atoi <= 0 {
-		return fallback
+	return "json"
+}
+
+// jsonResponse writes a response as JSON, failing with a server error rather than an empty body should anything in it,
+// such as an infinite length, have no JSON form.
+func jsonResponse(v any) events.LambdaFunctionURLResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		body, _ = json.Marshal(map[string]string{"message": "could not write the response as JSON: " + err.Error()})
+		return events.LambdaFunctionURLResponse{StatusCode: http.StatusInternalServerError, Headers: headers, Body: string(body)}
 	}
-	return atoi
+	return events.LambdaFunctionURLResponse{StatusCode: http.StatusOK, Headers: headers, Body: string(body)}
+}
+
+func textResponse(contentType string, body string) events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{StatusCode: http.StatusOK, Headers: map[string]string{"Content-Type": contentType}, Body: body}
+}
+
+func attachmentResponse(contentType string, filename string, body string) events.LambdaFunctionURLResponse {
+	response := textResponse(contentType, body)
+	response.Headers["Content-Disposition"] = fmt.Sprintf("attachment; filename=%q", filename)
+	return response
+}
+
+func binaryResponse(contentType string, body []byte) events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{StatusCode: http.StatusOK, Headers: map[string]string{"Content-Type": contentType}, Body: base64.StdEncoding.EncodeToString(body), IsBase64Encoded: true}
 }
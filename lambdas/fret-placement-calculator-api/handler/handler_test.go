@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"testing"
 
@@ -12,100 +13,83 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_parseIntegerQueryParameter(t *testing.T) {
-	type args struct {
-		q        map[string]string
-		key      string
-		fallback int
-	}
-	tests := []struct {
-		name string
-		args args
-		want int
-	}{
-		{
-			name: "valid integer parameter",
-			args: args{
-				q:        map[string]string{"octaves": "3"},
-				key:      "octaves",
-				fallback: 1,
-			},
-			want: 3,
-		},
-		{
-			name: "missing parameter uses fallback",
-			args: args{
-				q:        map[string]string{},
-				key:      "octaves",
-				fallback: 2,
-			},
-			want: 2,
-		},
-		{
-			name: "invalid integer parameter uses fallback",
-			args: args{
-				q:        map[string]string{"octaves": "invalid"},
-				key:      "octaves",
-				fallback: 4,
-			},
-			want: 4,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := parseIntegerQueryParameter(tt.args.q, tt.args.key, tt.args.fallback); got != tt.want {
-				t.Errorf("parseIntegerQueryParameter() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
+var allowedTuningSystems = func() string {
+	ids, _ := json.Marshal(tuningSystems.IDs())
+	return string(ids)
+}()
 
 func Test_shouldReturnErrorWhenScaleLengthIsNotProvided(t *testing.T) {
 	// Given
 	// When
 	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
-		QueryStringParameters: map[string]string{},
+		QueryStringParameters: map[string]string{"tuningSystem": "saz"},
 	})
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"error":"a numeric scaleLength greater than zero is required"}`}, response)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"required","parameter":"scaleLength","reason":"is required"}]}`}, response)
 }
 
 func Test_shouldReturnErrorWhenScaleLengthIsZero(t *testing.T) {
 	// Given
 	// When
 	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
-		QueryStringParameters: map[string]string{"scaleLength": "0"},
+		QueryStringParameters: map[string]string{"scaleLength": "0", "tuningSystem": "saz"},
 	})
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"error":"a numeric scaleLength greater than zero is required"}`}, response)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"out_of_range","parameter":"scaleLength","reason":"must be greater than 0"}]}`}, response)
 }
 
 func Test_shouldReturnErrorWhenScaleLengthIsLessThanZero(t *testing.T) {
 	// Given
 	// When
 	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
-		QueryStringParameters: map[string]string{"scaleLength": "-100"},
+		QueryStringParameters: map[string]string{"scaleLength": "-100", "tuningSystem": "saz"},
 	})
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"error":"a numeric scaleLength greater than zero is required"}`}, response)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"out_of_range","parameter":"scaleLength","reason":"must be greater than 0"}]}`}, response)
 }
 
 func Test_shouldReturnErrorWhenScaleLengthIsNotANumber(t *testing.T) {
 	// Given
 	// When
 	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
-		QueryStringParameters: map[string]string{"scaleLength": "three"},
+		QueryStringParameters: map[string]string{"scaleLength": "three", "tuningSystem": "saz"},
 	})
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"error":"a numeric scaleLength greater than zero is required"}`}, response)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"invalid_type","parameter":"scaleLength","reason":"must be a number"}]}`}, response)
+}
+
+func Test_shouldReturnErrorWhenScaleLengthIsNotFinite(t *testing.T) {
+	for _, scaleLength := range []string{"NaN", "Inf", "-Inf", "1e309"} {
+		t.Run(scaleLength, func(t *testing.T) {
+			// Given
+			// When
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+				QueryStringParameters: map[string]string{"scaleLength": scaleLength, "tuningSystem": "equal"},
+			})
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"invalid_type","parameter":"scaleLength","reason":"must be a number"}]}`}, response)
+		})
+	}
+}
+
+func Test_jsonResponseShouldFailRatherThanReturnAnEmptyBody(t *testing.T) {
+	// Given
+	// When
+	response := jsonResponse(map[string]float64{"position": math.Inf(1)})
+
+	// Then
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Equal(t, `{"message":"could not write the response as JSON: json: unsupported value: +Inf"}`, response.Body)
 }
 
 func Test_ShouldReturnErrorWhenTuningSystemIsNotProvided(t *testing.T) {
@@ -117,7 +101,7 @@ func Test_ShouldReturnErrorWhenTuningSystemIsNotProvided(t *testing.T) {
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"error":"please provide a valid tuning system"}`}, response)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"required","parameter":"tuningSystem","reason":"is required","allowedValues":` + allowedTuningSystems + `}]}`}, response)
 }
 
 func Test_ShouldReturnErrorWhenTuningSystemIsInvalid(t *testing.T) {
@@ -129,16 +113,49 @@ func Test_ShouldReturnErrorWhenTuningSystemIsInvalid(t *testing.T) {
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"error":"please provide a valid tuning system"}`}, response)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"not_allowed","parameter":"tuningSystem","reason":"must be one of the allowed values","allowedValues":` + allowedTuningSystems + `}]}`}, response)
+}
+
+func Test_ShouldReportEveryInvalidParameterTogether(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "long", "tuningSystem": "equal", "divisions": "-3", "octaves": "abc", "units": "cubits"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assert.Equal(t, headers, response.Headers)
+
+	var body ValidationErrors
+	_ = json.Unmarshal([]byte(response.Body), &body)
+	assert.Equal(t, []ValidationError{
+		{Code: InvalidTypeError, Parameter: "scaleLength", Reason: "must be a number"},
+		{Code: InvalidTypeError, Parameter: "octaves", Reason: "must be an integer"},
+		{Code: NotAllowedError, Parameter: "units", Reason: "must be one of the allowed values", AllowedValues: []string{"mm", "cm", "in"}},
+		{Code: OutOfRangeError, Parameter: "divisions", Reason: "must be between 1 and 1200"},
+	}, body.Errors)
 }
 
-func Test_ShouldDefaultToIonianIfNonSensicalPtolemyDiatonicScaleIsProvided(t *testing.T) {
+func Test_ShouldReturnErrorIfNonSensicalPtolemyDiatonicModeIsProvided(t *testing.T) {
 	// Given
 	// When
 	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
 		QueryStringParameters: map[string]string{"scaleLength": "540", "tuningSystem": "ptolemy", "diatonicMode": "Athenian"},
 	})
 
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"not_allowed","parameter":"diatonicMode","reason":"must be one of the allowed values","allowedValues":["Ionian","Dorian","Phrygian","Lydian","Mixolydian","Aeolian","Locrian"]}]}`}, response)
+}
+
+func Test_ShouldDefaultToIonianIfNoPtolemyDiatonicModeIsProvided(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "540", "tuningSystem": "ptolemy"},
+	})
 	// Then
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, response.StatusCode)
@@ -523,3 +540,91 @@ func Test_ShouldReturnSazPlacementsWithProvidedScaleLength(t *testing.T) {
 	assert.Equal(t, "Fret positions based on Turkish Saz tuning ratios.", fretboard.Description)
 	assert.Equal(t, 18, len(fretboard.Frets))
 }
+
+func Test_ShouldReturnCustomScalePlacementsFromRatiosAndCents(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "7:6,498.04,3:2,7:4"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	fretboard := instruments.Fretboard{}
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Custom", fretboard.System)
+	assert.Equal(t, "Fret positions based on a custom scale of 5 steps repeating at 2:1.", fretboard.Description)
+	assert.Equal(t, []instruments.Fret{
+		{Label: "1:1", Position: 0, Comment: "Perfect Unison", Interval: "1:1"},
+		{Label: "7:6", Position: 85.71, Interval: "7:6"},
+		{Label: "498.04 cents", Position: 150},
+		{Label: "3:2", Position: 200, Comment: "Perfect Fifth"},
+		{Label: "7:4", Position: 257.14, Comment: "Septimal (Harmonic) Minor Seventh", Interval: "7:6"},
+		{Label: "2:1", Position: 300, Comment: "Perfect Octave", Interval: "8:7"},
+	}, fretboard.Frets)
+}
+
+func Test_ShouldRepeatCustomScaleAtTheRequestedPeriod(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "250,500", "period": "750", "octaves": "2"},
+	})
+
+	// Then
+	fretboard := instruments.Fretboard{}
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Fret positions based on a custom scale of 3 steps repeating at 750.", fretboard.Description)
+	assert.Equal(t, 7, len(fretboard.Frets))
+	assert.Equal(t, instruments.Fret{Label: "0.00 cents", Position: 0}, fretboard.Frets[0])
+	assert.Equal(t, instruments.Fret{Label: "750.00 cents", Position: 210.95}, fretboard.Frets[3])
+	assert.Equal(t, instruments.Fret{Label: "1500.00 cents", Position: 347.73}, fretboard.Frets[6])
+}
+
+func Test_ShouldReturnErrorIfCustomScaleIsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "missing intervals",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "custom"},
+			body:  `{"errors":[{"code":"required","parameter":"intervals","reason":"is required"}]}`,
+		},
+		{
+			name:  "unparseable interval",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "7:6,fifth"},
+			body:  `{"errors":[{"code":"invalid_type","parameter":"intervals","reason":"must be a comma-separated list of ratios such as 7:6 or sizes in cents such as 266.87"}]}`,
+		},
+		{
+			name:  "descending intervals",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "3:2,5:4"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"intervals","reason":"must be in ascending order and each larger than 1:1"}]}`,
+		},
+		{
+			name:  "unison period",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "5:4", "period": "1:1"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"period","reason":"must be larger than 1:1"}]}`,
+		},
+		{
+			name:  "intervals reaching the bridge",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "5:4,17:1", "period": "100000"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"intervals","reason":"must each be no larger than 4800 cents (16:1)"},{"code":"out_of_range","parameter":"period","reason":"must be no larger than 4800 cents (16:1)"}]}`,
+		},
+		{
+			name:  "intervals beyond the period",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "custom", "intervals": "5:4,3:2,7:4", "period": "3:2"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"intervals","reason":"must all be smaller than the period of 3:2"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
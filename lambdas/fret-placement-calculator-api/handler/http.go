@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ServeHTTP adapts ordinary HTTP requests to Lambda function URL requests, and the responses back again, so that the
+// calculator can be self-hosted without the Lambda runtime.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := lambdaFunctionURLRequestFrom(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.HandleRequest(r.Context(), request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(response.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(body)
+}
+
+func lambdaFunctionURLRequestFrom(r *http.Request) (events.LambdaFunctionURLRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return events.LambdaFunctionURLRequest{}, err
+	}
+	query, err := queryParametersFrom(r.URL.RawQuery)
+	if err != nil {
+		return events.LambdaFunctionURLRequest{}, err
+	}
+
+	request := events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               r.URL.Path,
+		RawQueryString:        r.URL.RawQuery,
+		Headers:               map[string]string{},
+		QueryStringParameters: query,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  r.RemoteAddr,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	// function URLs lower-case header names and join repeated headers and query parameters with commas
+	for key, values := range r.Header {
+		request.Headers[strings.ToLower(key)] = strings.Join(values, ",")
+	}
+
+	if utf8.Valid(body) {
+		request.Body = string(body)
+	} else {
+		request.Body = base64.StdEncoding.EncodeToString(body)
+		request.IsBase64Encoded = true
+	}
+	return request, nil
+}
+
+// queryParametersFrom parses a query string as function URLs do, joining repeated parameters with commas.  Unlike
+// url.ParseQuery it keeps semicolons within values, as in systems=equal(divisions=19;octaves=2), rather than dropping
+// the parameters holding them.
+func queryParametersFrom(rawQuery string) (map[string]string, error) {
+	parameters := map[string]string{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query parameter %q: %w", pair, err)
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, fmt.Errorf("invalid query parameter %q: %w", pair, err)
+		}
+		if previous, repeated := parameters[key]; repeated {
+			value = previous + "," + value
+		}
+		parameters[key] = value
+	}
+	return parameters, nil
+}
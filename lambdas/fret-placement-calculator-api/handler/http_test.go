@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mikebharris/music/instruments"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldServeFretPlacementsOverPlainHTTP(t *testing.T) {
+	// Given
+	recorder := httptest.NewRecorder()
+
+	// When
+	Handler{}.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?scaleLength=540&tuningSystem=saz", nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var fretboard instruments.Fretboard
+	_ = json.Unmarshal(recorder.Body.Bytes(), &fretboard)
+	assert.Equal(t, "Saz", fretboard.System)
+	assert.Equal(t, 18, len(fretboard.Frets))
+}
+
+func Test_ShouldPassHeadersThroughAndDecodeBinaryResponsesOverPlainHTTP(t *testing.T) {
+	// Given
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/?scaleLength=540&tuningSystem=saz&format=pdf", nil)
+
+	// When
+	Handler{}.ServeHTTP(recorder, request)
+
+	// Then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "%PDF-1.4\n", recorder.Body.String()[:9])
+}
+
+func Test_ShouldSelectFormatFromAcceptHeaderOverPlainHTTP(t *testing.T) {
+	// Given
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/?scaleLength=540&tuningSystem=saz", nil)
+	request.Header.Set("Accept", "image/svg+xml")
+
+	// When
+	Handler{}.ServeHTTP(recorder, request)
+
+	// Then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "image/svg+xml", recorder.Header().Get("Content-Type"))
+}
+
+func Test_ShouldReturnErrorsOverPlainHTTP(t *testing.T) {
+	// Given
+	recorder := httptest.NewRecorder()
+
+	// When
+	Handler{}.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?scaleLength=540", nil))
+
+	// Then
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), `{"code":"required","parameter":"tuningSystem"`)
+}
+
+func Test_ShouldKeepSemicolonsWithinParametersOverPlainHTTP(t *testing.T) {
+	// Given
+	recorder := httptest.NewRecorder()
+
+	// When
+	Handler{}.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/compare?scaleLength=600&systems=meantone,equal(divisions=19;octaves=2)", nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var comparison Comparison
+	_ = json.Unmarshal(recorder.Body.Bytes(), &comparison)
+	assert.Equal(t, 2, len(comparison.TuningSystems))
+	assert.Equal(t, "Fret positions based on 19-tone equal temperament.", comparison.TuningSystems[1].Description)
+}
+
+func Test_ShouldRejectMalformedQueryStringsOverPlainHTTP(t *testing.T) {
+	// Given
+	recorder := httptest.NewRecorder()
+
+	// When
+	Handler{}.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?scaleLength=540&tuningSystem=%zz", nil))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `invalid query parameter "tuningSystem=%zz"`)
+}
+
+func Test_queryParametersFromShouldJoinRepeatedParametersWithCommas(t *testing.T) {
+	// Given
+	// When
+	parameters, err := queryParametersFrom("openStrings=E2&openStrings=A2&units=in&flag&tonic=A%232+")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"openStrings": "E2,A2", "units": "in", "flag": "", "tonic": "A#2 "}, parameters)
+}
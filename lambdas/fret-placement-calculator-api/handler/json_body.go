@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func isJSONRequest(request events.LambdaFunctionURLRequest) bool {
+	mediaType, _, _ := mime.ParseMediaType(request.Headers["content-type"])
+	return request.RequestContext.HTTP.Method == http.MethodPost && mediaType == "application/json"
+}
+
+// jsonParametersFrom reads the parameters of a request POSTed as a JSON object in place of a query string.  Parameters
+// in the query string still apply unless the body gives its own.
+func jsonParametersFrom(request events.LambdaFunctionURLRequest, q map[string]string) (map[string]string, []ValidationError) {
+	body, err := bodyOf(request)
+	var object map[string]any
+	if err != nil || decodeJSON(body, &object) != nil || object == nil {
+		return nil, []ValidationError{{Code: InvalidTypeError, Parameter: "body", Reason: "must be a JSON object of parameters"}}
+	}
+	parameters, errors := parametersFromJSON(object)
+	applyDefaults(parameters, q)
+	return parameters, errors
+}
+
+// applyDefaults gives q each of the defaults that it doesn't already have.
+func applyDefaults(q map[string]string, defaults map[string]string) {
+	for name, value := range defaults {
+		if _, given := q[name]; !given {
+			q[name] = value
+		}
+	}
+}
+
+// decodeJSON decodes a body keeping numbers as they were written, so that they are validated just as they would be in
+// a query string.
+func decodeJSON(body []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// parametersFromJSON turns an object of parameters into the strings they would have been in a query string, with
+// arrays joined by commas.
+func parametersFromJSON(object map[string]any) (map[string]string, []ValidationError) {
+	q := map[string]string{}
+	var errors []ValidationError
+	for _, name := range sortedKeys(object) {
+		value, ok := jsonParameterValue(object[name])
+		if !ok {
+			errors = append(errors, ValidationError{Code: InvalidTypeError, Parameter: name, Reason: "must be a string, number, boolean or array of them"})
+			continue
+		}
+		if value != "" {
+			q[name] = value
+		}
+	}
+	return q, errors
+}
+
+func jsonParameterValue(value any) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case []any:
+		values := make([]string, len(value))
+		for i, element := range value {
+			if _, nested := element.([]any); nested {
+				return "", false
+			}
+			s, ok := jsonParameterValue(element)
+			if !ok {
+				return "", false
+			}
+			values[i] = s
+		}
+		return strings.Join(values, ","), true
+	default:
+		return "", false
+	}
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := slices.Collect(maps.Keys(object))
+	slices.Sort(keys)
+	return keys
+}
+
+// bodyOf returns the body of a request, decoded if the function URL base64-encoded it.
+func bodyOf(request events.LambdaFunctionURLRequest) ([]byte, error) {
+	if request.IsBase64Encoded {
+		return base64.StdEncoding.DecodeString(request.Body)
+	}
+	return []byte(request.Body), nil
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+	"github.com/stretchr/testify/assert"
+)
+
+func jsonRequest(body string, q map[string]string) events.LambdaFunctionURLRequest {
+	return events.LambdaFunctionURLRequest{
+		QueryStringParameters: q,
+		Headers:               map[string]string{"content-type": "application/json; charset=utf-8"},
+		Body:                  body,
+		RequestContext:        events.LambdaFunctionURLRequestContext{HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodPost}},
+	}
+}
+
+func Test_ShouldCalculateFretboardFromJSONBody(t *testing.T) {
+	// Given
+	body := `{"scaleLength": 600, "tuningSystem": "custom", "intervals": ["9:8", "5:4", 498.04], "octaves": 2}`
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), jsonRequest(body, nil))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var fretboard instruments.Fretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 600.0, fretboard.ScaleLength)
+	assert.Equal(t, 9, len(fretboard.Frets))
+	assert.Equal(t, "9:8", fretboard.Frets[1].Label)
+	assert.Equal(t, "498.04 cents", fretboard.Frets[3].Label)
+}
+
+func Test_ShouldGiveSameFretboardForJSONBodyAsForQueryString(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "equal", "divisions": "19", "format": "csv"}
+
+	// When
+	get, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+	post, _ := Handler{}.HandleRequest(context.Background(), jsonRequest(`{"scaleLength": 25.5, "units": "in", "tuningSystem": "equal", "divisions": 19}`, map[string]string{"format": "csv"}))
+
+	// Then
+	assert.Equal(t, get, post)
+}
+
+func Test_ShouldValidateJSONBodyAsQueryString(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "not json", body: "scaleLength=600", want: `{"errors":[{"code":"invalid_type","parameter":"body","reason":"must be a JSON object of parameters"}]}`},
+		{name: "array", body: `[{"scaleLength": 600}]`, want: `{"errors":[{"code":"invalid_type","parameter":"body","reason":"must be a JSON object of parameters"}]}`},
+		{name: "nested object", body: `{"scaleLength": {"value": 600}}`, want: `{"errors":[{"code":"invalid_type","parameter":"scaleLength","reason":"must be a string, number, boolean or array of them"}]}`},
+		{name: "invalid values", body: `{"scaleLength": -1, "tuningSystem": "equal", "divisions": 1.5}`, want: `{"errors":[{"code":"out_of_range","parameter":"scaleLength","reason":"must be greater than 0"},{"code":"invalid_type","parameter":"divisions","reason":"must be an integer"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), jsonRequest(tt.body, nil))
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.want}, response)
+		})
+	}
+}
+
+func Test_ShouldReadBase64EncodedJSONBody(t *testing.T) {
+	// Given
+	request := jsonRequest(base64.StdEncoding.EncodeToString([]byte(`{"scaleLength": 540, "tuningSystem": "saz"}`)), nil)
+	request.IsBase64Encoded = true
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), request)
+
+	// Then
+	var fretboard instruments.Fretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Saz", fretboard.System)
+}
+
+func Test_ShouldCompareTuningSystemsGivenAsJSONArray(t *testing.T) {
+	// Given
+	request := jsonRequest(`{"scaleLength": 600, "systems": ["meantone", "equal(divisions=19)"]}`, nil)
+	request.RawPath = "/compare"
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), request)
+
+	// Then
+	var comparison Comparison
+	_ = json.Unmarshal([]byte(response.Body), &comparison)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "equal", comparison.TuningSystems[1].ID)
+}
+
+func Test_parametersFromJSON(t *testing.T) {
+	// Given
+	var object map[string]any
+	decoder := json.NewDecoder(strings.NewReader(`{"scaleLength": 25.5, "divisions": 12, "label": "x", "tapered": true, "intervals": ["7:6", 386.31], "openString": null, "nested": {"a": 1}, "matrix": [[1]]}`))
+	decoder.UseNumber()
+	_ = decoder.Decode(&object)
+
+	// When
+	q, errors := parametersFromJSON(object)
+
+	// Then
+	assert.Equal(t, map[string]string{"scaleLength": "25.5", "divisions": "12", "label": "x", "tapered": "true", "intervals": "7:6,386.31"}, q)
+	assert.Equal(t, []ValidationError{
+		{Code: InvalidTypeError, Parameter: "matrix", Reason: "must be a string, number, boolean or array of them"},
+		{Code: InvalidTypeError, Parameter: "nested", Reason: "must be a string, number, boolean or array of them"},
+	}, errors)
+}
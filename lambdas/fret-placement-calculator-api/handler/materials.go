@@ -0,0 +1,76 @@
+package handler
+
+import "math"
+
+// stringMaterial holds approximate properties of a kind of string.  Wound strings are modelled as a solid string of
+// the same diameter with an effective density, whose stiffness comes from a core of a fraction of the diameter.
+type stringMaterial struct {
+	id            string
+	density       float64      // kg/m³
+	youngsModulus float64      // of the core, in Pa
+	coreRatio     float64      // diameter of the core over that of the string
+	guitar        tensionRange // for shorter scales
+	bass          tensionRange // for scales at least as long as bassScaleLength
+}
+
+// tensionRange is the range of tensions in N within which a string plays well: below it the string feels slack and
+// buzzes, and above it the string is hard to fret and likely to break.
+type tensionRange struct {
+	minimum float64
+	maximum float64
+}
+
+// bassScaleLength is the scale length in metres, that of a short-scale bass, from which strings are held to the
+// tensions of bass strings rather than guitar strings.
+const bassScaleLength = 0.76
+
+var stringMaterials = []stringMaterial{
+	{id: "plainSteel", density: 7850, youngsModulus: 200e9, coreRatio: 1, guitar: tensionRange{45, 135}, bass: tensionRange{110, 270}},
+	{id: "nickelWound", density: 5800, youngsModulus: 200e9, coreRatio: 0.45, guitar: tensionRange{45, 135}, bass: tensionRange{110, 270}},
+	{id: "phosphorBronzeWound", density: 6300, youngsModulus: 200e9, coreRatio: 0.45, guitar: tensionRange{45, 135}, bass: tensionRange{110, 270}},
+	{id: "nylon", density: 1140, youngsModulus: 4.5e9, coreRatio: 1, guitar: tensionRange{35, 100}, bass: tensionRange{60, 180}},
+	{id: "gut", density: 1300, youngsModulus: 5e9, coreRatio: 1, guitar: tensionRange{25, 90}, bass: tensionRange{100, 300}},
+}
+
+// playableRange gives the range of tensions suited to a string of the material on a scale of the given length in
+// metres, and whether it is that of a bass.
+func (m stringMaterial) playableRange(scaleLength float64) (tensionRange, bool) {
+	if scaleLength >= bassScaleLength {
+		return m.bass, true
+	}
+	return m.guitar, false
+}
+
+func stringMaterialIDs() []string {
+	var ids []string
+	for _, material := range stringMaterials {
+		ids = append(ids, material.id)
+	}
+	return ids
+}
+
+func lookupStringMaterial(id string) stringMaterial {
+	for _, material := range stringMaterials {
+		if material.id == id {
+			return material
+		}
+	}
+	return stringMaterials[0]
+}
+
+// unitWeight is the mass per metre of a string of the given diameter in metres.
+func (m stringMaterial) unitWeight(diameter float64) float64 {
+	return m.density * math.Pi * diameter * diameter / 4
+}
+
+// stretchStiffness is the force in N needed to stretch a string of the given diameter by its own length.
+func (m stringMaterial) stretchStiffness(diameter float64) float64 {
+	core := m.coreRatio * diameter
+	return m.youngsModulus * math.Pi * core * core / 4
+}
+
+// bendingStiffness is the product of Young's modulus and the second moment of area of the core, in N m².
+func (m stringMaterial) bendingStiffness(diameter float64) float64 {
+	core := m.coreRatio * diameter
+	return m.youngsModulus * math.Pi * math.Pow(core, 4) / 64
+}
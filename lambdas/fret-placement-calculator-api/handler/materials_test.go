@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_plainSteelShouldWeighWhatStringMakersQuote(t *testing.T) {
+	// Given
+	steel := lookupStringMaterial("plainSteel")
+
+	// When
+	unitWeight := steel.unitWeight(0.010 * 0.0254)
+
+	// Then
+	assert.InDelta(t, 0.000396, unitWeight, 0.000005) // 0.00002215 lb/in for a .010 plain steel string
+}
+
+func Test_woundStringsShouldBeStiffenedOnlyByTheirCore(t *testing.T) {
+	// Given
+	steel, wound := lookupStringMaterial("plainSteel"), lookupStringMaterial("nickelWound")
+
+	// When
+	// Then
+	assert.Less(t, wound.unitWeight(0.001), steel.unitWeight(0.001))
+	assert.InDelta(t, steel.stretchStiffness(0.00045), wound.stretchStiffness(0.001), 0.001)
+	assert.InDelta(t, steel.bendingStiffness(0.00045), wound.bendingStiffness(0.001), 1e-12)
+}
+
+func Test_unknownMaterialsShouldBeTakenAsPlainSteel(t *testing.T) {
+	assert.Equal(t, "plainSteel", lookupStringMaterial("unobtainium").id)
+	assert.Equal(t, []string{"plainSteel", "nickelWound", "phosphorBronzeWound", "nylon", "gut"}, stringMaterialIDs())
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+)
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type FannedString struct {
+	Number      int     `json:"number"`
+	ScaleLength float64 `json:"scaleLength"`
+	Nut         Point   `json:"nut"`
+	Bridge      Point   `json:"bridge"`
+	Frets       []Point `json:"frets"`
+}
+
+type FretLine struct {
+	Fret   int     `json:"fret"`
+	Label  string  `json:"label"`
+	Angle  float64 `json:"angle"`
+	Bass   Point   `json:"bass"`
+	Treble Point   `json:"treble"`
+}
+
+// MultiscaleFretboard describes a fanned-fret fretboard.  The embedded Fretboard is that of the bass string.
+// Coordinates are measured along the centre line (x) from the furthest-back point of the nut and across it (y)
+// from the centre line, with the bass side negative.  Fret line angles are in degrees from perpendicular to the
+// centre line, positive where the treble end lies closer to the bridge than the bass end.
+type MultiscaleFretboard struct {
+	instruments.Fretboard
+	Units             string         `json:"units"`
+	BassScaleLength   float64        `json:"bassScaleLength"`
+	TrebleScaleLength float64        `json:"trebleScaleLength"`
+	NutWidth          float64        `json:"nutWidth"`
+	BridgeWidth       float64        `json:"bridgeWidth"`
+	PerpendicularFret int            `json:"perpendicularFret"`
+	Strings           []FannedString `json:"strings"`
+	FretLines         []FretLine     `json:"fretLines"`
+}
+
+func isMultiscaleRequest(q map[string]string) bool {
+	return q["bassScaleLength"] != "" || q["trebleScaleLength"] != ""
+}
+
+var multiscaleParameters = []Parameter{
+	{Name: "bassScaleLength", Type: NumberParameter, Description: "Scale length of the lowest string", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "trebleScaleLength", Type: NumberParameter, Description: "Scale length of the highest string", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "strings", Type: IntegerParameter, Description: "Number of strings", Required: true, Minimum: bound(2), Maximum: bound(24)},
+	{Name: "nutWidth", Type: NumberParameter, Description: "Distance between the outer strings at the nut", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "bridgeWidth", Type: NumberParameter, Description: "Distance between the outer strings at the bridge", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "perpendicularFret", Type: IntegerParameter, Description: "Fret that lies square to the centre line", Default: 0, Minimum: bound(0)},
+}
+
+func (h Handler) handleMultiscaleRequest(q map[string]string) events.LambdaFunctionURLResponse {
+	v := newValidator(q)
+	for _, p := range commonParameters() {
+		if p.Name == "tuningSystem" || p.Name == "octaves" || p.Name == "units" {
+			v.parse(p)
+		}
+	}
+	system, _ := v.parseTuningSystem()
+	v.parse(multiscaleParameters...)
+	if format := v.q["format"]; format != "" && format != "json" {
+		v.addError(ValidationError{Code: NotAllowedError, Parameter: "format", Reason: "must be json for multiscale fretboards", AllowedValues: []string{"json"}})
+	}
+	if v.valid() {
+		v.checkStringSpread(min(v.args.number("bassScaleLength"), v.args.number("trebleScaleLength")))
+	}
+	if !v.valid() {
+		return v.errorResponse()
+	}
+
+	numberOfStrings := v.args.integer("strings")
+	scale := system.scale(v.args.integer("octaves"), v.args)
+	fretboards := make([]instruments.Fretboard, numberOfStrings)
+	for i := range fretboards {
+		fretboards[i] = scale.fretboard(interpolate(v.args.number("bassScaleLength"), v.args.number("trebleScaleLength"), i, numberOfStrings), v.args.text("units"))
+	}
+
+	perpendicularFret := v.args.integer("perpendicularFret")
+	if perpendicularFret >= len(fretboards[0].Frets) {
+		return validationErrorResponse(ValidationError{Code: OutOfRangeError, Parameter: "perpendicularFret", Reason: fmt.Sprintf("must be one of the frets of the tuning system (0 to %d)", len(fretboards[0].Frets)-1)})
+	}
+
+	multiscale := newMultiscaleFretboard(fretboards, v.args.number("nutWidth"), v.args.number("bridgeWidth"), perpendicularFret, v.args.text("units"))
+	return jsonResponse(multiscale)
+}
+
+// checkStringSpread makes sure that the outer strings, fanning out from the nut to the bridge, can still be as long as
+// the shortest scale length.
+func (v *validator) checkStringSpread(scaleLength float64) {
+	if math.Abs(v.args.number("bridgeWidth")-v.args.number("nutWidth"))/2 >= scaleLength {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "bridgeWidth", Reason: "must differ from nutWidth by less than twice the scale length"})
+	}
+}
+
+func newMultiscaleFretboard(fretboards []instruments.Fretboard, nutWidth, bridgeWidth float64, perpendicularFret int, units string) MultiscaleFretboard {
+	numberOfStrings := len(fretboards)
+	multiscale := MultiscaleFretboard{
+		Fretboard:         fretboards[0],
+		Units:             units,
+		BassScaleLength:   fretboards[0].ScaleLength,
+		TrebleScaleLength: fretboards[numberOfStrings-1].ScaleLength,
+		NutWidth:          nutWidth,
+		BridgeWidth:       bridgeWidth,
+		PerpendicularFret: perpendicularFret,
+	}
+
+	// lay each string out with its perpendicular fret at x = 0, then shift everything so the nut starts at x = 0
+	var offset float64
+	for i, fretboard := range fretboards {
+		length := fretboard.ScaleLength
+		nutY := interpolate(-nutWidth/2, nutWidth/2, i, numberOfStrings)
+		bridgeY := interpolate(-bridgeWidth/2, bridgeWidth/2, i, numberOfStrings)
+		span := math.Sqrt(length*length - (bridgeY-nutY)*(bridgeY-nutY))
+		nutX := -span * fretboard.Frets[perpendicularFret].Position / length
+		offset = math.Max(offset, -nutX)
+
+		s := FannedString{
+			Number:      i + 1,
+			ScaleLength: length,
+			Nut:         Point{X: nutX, Y: nutY},
+			Bridge:      Point{X: nutX + span, Y: bridgeY},
+		}
+		for _, fret := range fretboard.Frets {
+			s.Frets = append(s.Frets, Point{
+				X: nutX + span*fret.Position/length,
+				Y: nutY + (bridgeY-nutY)*fret.Position/length,
+			})
+		}
+		multiscale.Strings = append(multiscale.Strings, s)
+	}
+
+	for i := range multiscale.Strings {
+		s := &multiscale.Strings[i]
+		s.Nut = s.Nut.shiftedAndRounded(offset, units)
+		s.Bridge = s.Bridge.shiftedAndRounded(offset, units)
+		for j := range s.Frets {
+			s.Frets[j] = s.Frets[j].shiftedAndRounded(offset, units)
+		}
+	}
+
+	bass, treble := multiscale.Strings[0], multiscale.Strings[numberOfStrings-1]
+	for i, fret := range fretboards[0].Frets {
+		multiscale.FretLines = append(multiscale.FretLines, FretLine{
+			Fret:   i,
+			Label:  fret.Label,
+			Angle:  roundToHundredths(math.Atan2(treble.Frets[i].X-bass.Frets[i].X, treble.Frets[i].Y-bass.Frets[i].Y) * 180 / math.Pi),
+			Bass:   bass.Frets[i],
+			Treble: treble.Frets[i],
+		})
+	}
+	return multiscale
+}
+
+func interpolate(from, to float64, i, n int) float64 {
+	return from + (to-from)*float64(i)/float64(n-1)
+}
+
+func (p Point) shiftedAndRounded(dx float64, units string) Point {
+	return Point{X: roundToPrecisionOf(p.X+dx, units), Y: roundToPrecisionOf(p.Y, units)}
+}
+
+func roundToHundredths(f float64) float64 {
+	return math.Round(f*100)/100 + 0 // adding zero turns -0 into 0
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnFannedFretsWithPerpendicularFretSquareToTheCentreLine(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"bassScaleLength": "880", "trebleScaleLength": "800", "strings": "5", "nutWidth": "45", "bridgeWidth": "90", "perpendicularFret": "7", "tuningSystem": "equal", "divisions": "12"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, headers, response.Headers)
+
+	var fretboard MultiscaleFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Equal Temperament", fretboard.System)
+	assert.Equal(t, 880.0, fretboard.ScaleLength)
+	assert.Equal(t, 880.0, fretboard.BassScaleLength)
+	assert.Equal(t, 800.0, fretboard.TrebleScaleLength)
+	assert.Equal(t, 13, len(fretboard.Frets))
+	assert.Equal(t, 5, len(fretboard.Strings))
+	assert.Equal(t, 13, len(fretboard.FretLines))
+
+	assert.Equal(t, 860.0, fretboard.Strings[1].ScaleLength)
+	assert.Equal(t, Point{X: 0, Y: -22.5}, fretboard.Strings[0].Nut)
+	assert.Equal(t, -45.0, fretboard.Strings[0].Bridge.Y)
+	assert.Equal(t, 45.0, fretboard.Strings[4].Bridge.Y)
+
+	assert.Equal(t, 0.0, fretboard.FretLines[7].Angle)
+	assert.Equal(t, fretboard.FretLines[7].Bass.X, fretboard.FretLines[7].Treble.X)
+	assert.Greater(t, fretboard.FretLines[0].Angle, 0.0)
+	assert.Less(t, fretboard.FretLines[12].Angle, 0.0)
+	for _, s := range fretboard.Strings {
+		assert.InDelta(t, fretboard.FretLines[7].Bass.X, s.Frets[7].X, 0.01)
+	}
+}
+
+func Test_ShouldReturnFannedFretsForJustTuningSystems(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"bassScaleLength": "660", "trebleScaleLength": "630", "strings": "6", "nutWidth": "43", "bridgeWidth": "53", "tuningSystem": "ptolemy"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var fretboard MultiscaleFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Ptolemy Intense Diatonic", fretboard.System)
+	assert.Equal(t, 0, fretboard.PerpendicularFret)
+	assert.Equal(t, 0.0, fretboard.FretLines[0].Angle)
+	assert.Equal(t, 329.99, fretboard.Strings[0].Frets[7].X)
+	assert.Equal(t, 8, len(fretboard.Strings[5].Frets))
+}
+
+func Test_ShouldReturnErrorWhenMultiscaleGeometryIsIncomplete(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "missing treble scale length",
+			query: map[string]string{"bassScaleLength": "880", "strings": "5", "nutWidth": "45", "bridgeWidth": "90", "tuningSystem": "saz"},
+			body:  `{"errors":[{"code":"required","parameter":"trebleScaleLength","reason":"is required"}]}`,
+		},
+		{
+			name:  "single string",
+			query: map[string]string{"bassScaleLength": "880", "trebleScaleLength": "800", "strings": "1", "nutWidth": "45", "bridgeWidth": "90", "tuningSystem": "saz"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"strings","reason":"must be between 2 and 24"}]}`,
+		},
+		{
+			name:  "missing bridge width",
+			query: map[string]string{"bassScaleLength": "880", "trebleScaleLength": "800", "strings": "5", "nutWidth": "45", "tuningSystem": "saz"},
+			body:  `{"errors":[{"code":"required","parameter":"bridgeWidth","reason":"is required"}]}`,
+		},
+		{
+			name:  "perpendicular fret beyond the end of the fretboard",
+			query: map[string]string{"bassScaleLength": "880", "trebleScaleLength": "800", "strings": "5", "nutWidth": "45", "bridgeWidth": "90", "tuningSystem": "equal", "divisions": "12", "perpendicularFret": "13"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"perpendicularFret","reason":"must be one of the frets of the tuning system (0 to 12)"}]}`,
+		},
+		{
+			name:  "strings spread wider than they are long",
+			query: map[string]string{"bassScaleLength": "650", "trebleScaleLength": "600", "strings": "6", "nutWidth": "40", "bridgeWidth": "5000", "tuningSystem": "equal"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"bridgeWidth","reason":"must differ from nutWidth by less than twice the scale length"}]}`,
+		},
+		{
+			name:  "drawing formats",
+			query: map[string]string{"bassScaleLength": "650", "trebleScaleLength": "600", "strings": "6", "nutWidth": "40", "bridgeWidth": "50", "tuningSystem": "equal", "format": "svg"},
+			body:  `{"errors":[{"code":"not_allowed","parameter":"format","reason":"must be json for multiscale fretboards","allowedValues":["json"]}]}`,
+		},
+		{
+			name:  "missing tuning system",
+			query: map[string]string{"bassScaleLength": "880", "trebleScaleLength": "800", "strings": "5", "nutWidth": "45", "bridgeWidth": "90"},
+			body:  `{"errors":[{"code":"required","parameter":"tuningSystem","reason":"is required","allowedValues":` + allowedTuningSystems + `}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
+
+func Test_ShouldGiveFannedFretsToThousandthsInInches(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"bassScaleLength": "27", "trebleScaleLength": "25.5", "strings": "6", "nutWidth": "1.7", "bridgeWidth": "2.1", "tuningSystem": "equal", "divisions": "12", "units": "in"},
+	})
+
+	// Then
+	var fretboard MultiscaleFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 1.515, fretboard.Frets[1].Position)
+	assert.Equal(t, Point{X: 1.515, Y: -0.861}, fretboard.Strings[0].Frets[1])
+	assert.Equal(t, Point{X: 1.499, Y: -0.517}, fretboard.Strings[1].Frets[1])
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+)
+
+// StringNotes gives the note sounded at every fret of one string of an instrument.
+type StringNotes struct {
+	String     int         `json:"string"`
+	OpenString string      `json:"openString"`
+	Notes      []FretPitch `json:"notes"`
+}
+
+// NoteMap lays out the notes of a whole instrument, whose strings share the frets of the embedded Fretboard.
+type NoteMap struct {
+	instruments.Fretboard
+	Units   string        `json:"units"`
+	Strings []StringNotes `json:"strings"`
+}
+
+var noteMapParameters = []Parameter{
+	{Name: "openStrings", Type: PitchListParameter, Description: "Comma-separated pitches of the open strings, as note names and octaves (E2,A2,D3,G3,B3,E4) or frequencies in Hz", Required: true},
+	{Name: "referenceFrequency", Type: NumberParameter, Description: "Frequency of A4 in Hz, from which note names are tuned and measured", Default: defaultReferenceFrequency, ExclusiveMinimum: bound(0)},
+}
+
+func (h Handler) handleNoteMapRequest(q map[string]string) events.LambdaFunctionURLResponse {
+	v := newValidator(q)
+	for _, p := range commonParameters() {
+		if p.Name != "format" {
+			v.parse(p)
+		}
+	}
+	v.checkMaximumPosition()
+	system, _ := v.parseTuningSystem()
+	v.parse(noteMapParameters...)
+	if !v.valid() {
+		return v.errorResponse()
+	}
+	return jsonResponse(newNoteMap(v.args, system, strings.Split(v.q["openStrings"], ",")))
+}
+
+func newNoteMap(args arguments, system TuningSystem, openStrings []string) NoteMap {
+	scale := system.scale(args.integer("octaves"), args)
+	noteMap := NoteMap{
+		Fretboard: scale.fretboard(args.number("scaleLength"), args.text("units")),
+		Units:     args.text("units"),
+	}
+	referenceFrequency := args.number("referenceFrequency")
+	ratios := scale.ratios()
+	for i, openString := range args.pitches("openStrings") {
+		noteMap.Strings = append(noteMap.Strings, StringNotes{
+			String:     i + 1,
+			OpenString: strings.TrimSpace(openStrings[i]),
+			Notes:      newFretPitches(openString.hz(referenceFrequency), referenceFrequency, ratios),
+		})
+	}
+	return noteMap
+}
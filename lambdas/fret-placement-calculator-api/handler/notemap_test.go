@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldMapNotesOfEveryStringAtEveryFret(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		RawPath:               "/noteMap",
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "equal", "divisions": "31", "openStrings": "E2,A2,D3,G3,B3,E4"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var noteMap NoteMap
+	_ = json.Unmarshal([]byte(response.Body), &noteMap)
+	assert.Equal(t, "mm", noteMap.Units)
+	assert.Equal(t, 32, len(noteMap.Frets))
+	assert.Equal(t, 6, len(noteMap.Strings))
+	for _, s := range noteMap.Strings {
+		assert.Equal(t, 32, len(s.Notes))
+	}
+	assert.Equal(t, "A2", noteMap.Strings[1].OpenString)
+	assert.Equal(t, FretPitch{Fret: 0, Frequency: 110, NearestNote: "A2"}, noteMap.Strings[1].Notes[0])
+	assert.Equal(t, FretPitch{Fret: 13, Frequency: 147.11, Cents: 503.23, NearestNote: "D3", Deviation: 3.23}, noteMap.Strings[1].Notes[13])
+	assert.Equal(t, FretPitch{Fret: 31, Frequency: 659.26, Cents: 1200, NearestNote: "E5"}, noteMap.Strings[5].Notes[31])
+}
+
+func Test_ShouldMapNotesFromOpenStringFrequenciesAndReferenceFrequency(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		RawPath:               "/noteMap/",
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "12", "openStrings": "G3,216Hz", "referenceFrequency": "432"},
+	})
+
+	// Then
+	var noteMap NoteMap
+	_ = json.Unmarshal([]byte(response.Body), &noteMap)
+	assert.Equal(t, FretPitch{Fret: 2, Frequency: 216, Cents: 200, NearestNote: "A3"}, noteMap.Strings[0].Notes[2])
+	assert.Equal(t, FretPitch{Fret: 0, Frequency: 216, NearestNote: "A3"}, noteMap.Strings[1].Notes[0])
+}
+
+func Test_ShouldReturnErrorsWhenNoteMapIsInvalid(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		RawPath:               "/noteMap",
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "equal", "divisions": "x", "openStrings": "E2,Q"},
+	})
+
+	// Then
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"invalid_type","parameter":"divisions","reason":"must be an integer"},{"code":"invalid_type","parameter":"openStrings","reason":"must be a comma-separated list of note names and octaves such as E2, F#3 or Bb1, or frequencies in Hz"}]}`}, response)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultReferenceFrequency = 440.0
+
+var noteNames = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+var naturalNotes = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// pitch is either a named note, held as its distance in 12-TET semitones from A4, or a frequency in Hz.
+type pitch struct {
+	semitones float64
+	frequency float64
+}
+
+// parsePitch accepts note names with an octave in scientific pitch notation, such as E2, F#3 or Bb1, or frequencies
+// such as 82.41 or 82.41Hz.
+func parsePitch(s string) (pitch, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return pitch{}, errors.New("empty pitch")
+	}
+	if frequency, ok := strings.CutSuffix(strings.ToLower(s), "hz"); ok || (s[0] >= '0' && s[0] <= '9') {
+		f, err := parseFiniteFloat(strings.TrimSpace(frequency))
+		if err != nil || f <= 0 {
+			return pitch{}, fmt.Errorf("invalid frequency %s", s)
+		}
+		return pitch{frequency: f}, nil
+	}
+
+	semitone, ok := naturalNotes[strings.ToUpper(s)[0]]
+	if !ok {
+		return pitch{}, fmt.Errorf("invalid note name %s", s)
+	}
+	rest := s[1:]
+	for {
+		switch {
+		case strings.HasPrefix(rest, "#"), strings.HasPrefix(rest, "♯"):
+			semitone++
+		case strings.HasPrefix(rest, "b"), strings.HasPrefix(rest, "♭"):
+			semitone--
+		default:
+			octave, err := strconv.Atoi(rest)
+			if err != nil {
+				return pitch{}, fmt.Errorf("invalid octave in %s", s)
+			}
+			return pitch{semitones: float64(12*(octave+1) + semitone - 69)}, nil
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		rest = rest[size:]
+	}
+}
+
+func (p pitch) hz(referenceFrequency float64) float64 {
+	if p.frequency != 0 {
+		return p.frequency
+	}
+	return referenceFrequency * math.Pow(2, p.semitones/12)
+}
+
+// nearestNote names the 12-TET note nearest to a frequency and how many cents the frequency lies above it.
+func nearestNote(frequency, referenceFrequency float64) (string, float64) {
+	cents := 1200 * math.Log2(frequency/referenceFrequency)
+	semitones := math.Round(cents / 100)
+	midi := 69 + int(semitones)
+	octave := int(math.Floor(float64(midi)/12)) - 1
+	return fmt.Sprintf("%s%d", noteNames[((midi%12)+12)%12], octave), cents - 100*semitones
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parsePitch(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: "A4", want: 440},
+		{input: "E2", want: 82.41},
+		{input: "F#3", want: 185},
+		{input: "Bb1", want: 58.27},
+		{input: "b♭1", want: 58.27},
+		{input: "C-1", want: 8.18},
+		{input: "Cbb4", want: 233.08},
+		{input: "82.41", want: 82.41},
+		{input: "110 Hz", want: 110},
+		{input: "H4", wantErr: true},
+		{input: "E", wantErr: true},
+		{input: "E#x", wantErr: true},
+		{input: "0Hz", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parsePitch(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if err == nil {
+				assert.InDelta(t, tt.want, got.hz(440), 0.005)
+			}
+		})
+	}
+}
+
+func Test_namedPitchesShouldFollowTheReferenceFrequency(t *testing.T) {
+	// Given
+	a3, _ := parsePitch("A3")
+	hertz, _ := parsePitch("220")
+
+	// When
+	// Then
+	assert.Equal(t, 207.5, a3.hz(415))
+	assert.Equal(t, 220.0, hertz.hz(415))
+}
+
+func Test_nearestNote(t *testing.T) {
+	tests := []struct {
+		frequency float64
+		note      string
+		deviation float64
+	}{
+		{frequency: 440, note: "A4", deviation: 0},
+		{frequency: 82.4069, note: "E2", deviation: 0},
+		{frequency: 275, note: "C#4", deviation: -13.69},
+		{frequency: 8.1758, note: "C-1", deviation: 0},
+		{frequency: 452.89, note: "A4", deviation: 49.99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.note, func(t *testing.T) {
+			note, deviation := nearestNote(tt.frequency, 440)
+			assert.Equal(t, tt.note, note)
+			assert.InDelta(t, tt.deviation, deviation, 0.05)
+		})
+	}
+}
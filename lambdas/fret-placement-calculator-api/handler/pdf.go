@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mikebharris/music/instruments"
+)
+
+const (
+	pointsPerInch   = 72.0
+	pdfMargin       = 36.0
+	pdfFontSize     = 9.0
+	pdfRowHeight    = 13.0
+	pdfTitleSize    = 14.0
+	pdfRulerSpacing = 24.0
+)
+
+var paperSizes = map[string][2]float64{
+	"a4":     {841.89, 595.28},
+	"letter": {792, 612},
+}
+
+var pdfParameters = []Parameter{
+	{Name: "paper", Type: StringParameter, Description: "Paper size", Default: "a4", AllowedValues: []string{"a4", "letter"}},
+}
+
+// pdfDocument is a minimal PDF writer that knows just enough to draw lines, rectangles and Helvetica text.
+type pdfDocument struct {
+	width  float64
+	height float64
+	pages  []*pdfPage
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (d *pdfDocument) newPage() *pdfPage {
+	page := &pdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%s %s m %s %s l S\n", formatFloat(x1), formatFloat(y1), formatFloat(x2), formatFloat(y2))
+}
+
+func (p *pdfPage) rectangle(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re S\n", formatFloat(x), formatFloat(y), formatFloat(width), formatFloat(height))
+}
+
+func (p *pdfPage) lineWidth(width float64) {
+	fmt.Fprintf(&p.content, "%s w\n", formatFloat(width))
+}
+
+func (p *pdfPage) dashed(dashed bool) {
+	if dashed {
+		p.content.WriteString("[4 2] 0 d\n")
+	} else {
+		p.content.WriteString("[] 0 d\n")
+	}
+}
+
+func (p *pdfPage) text(x, y, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", formatFloat(size), formatFloat(x), formatFloat(y), escapePDFString(s))
+}
+
+func (p *pdfPage) rotatedText(x, y, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf 0 1 -1 0 %s %s Tm (%s) Tj ET\n", formatFloat(size), formatFloat(x), formatFloat(y), escapePDFString(s))
+}
+
+func (p *pdfPage) clipAndTranslate(x, y, width, height, dx float64) {
+	fmt.Fprintf(&p.content, "q %s %s %s %s re W n 1 0 0 1 %s 0 cm\n", formatFloat(x), formatFloat(y), formatFloat(width), formatFloat(height), formatFloat(dx))
+}
+
+func (p *pdfPage) restore() {
+	p.content.WriteString("Q\n")
+}
+
+func escapePDFString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (d *pdfDocument) bytes() []byte {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", formatFloat(d.width), formatFloat(d.height), 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+// renderPDF produces a printable document: fret table pages headed by a calibration square, followed by a 1:1
+// template tiled across as many landscape pages as the scale length needs, with alignment marks where they join.
+func renderPDF(fretboard instruments.Fretboard, units string, taper taper, paper string) []byte {
+	size := paperSizes[paper]
+	d := &pdfDocument{width: size[0], height: size[1]}
+	pointsPerUnit := pointsPerInch * metresPer(units) / metresPer("in")
+	renderPDFTable(d, fretboard, units, pointsPerUnit)
+	renderPDFTemplate(d, fretboard, units, taper, pointsPerUnit)
+	return d.bytes()
+}
+
+func renderPDFTable(d *pdfDocument, fretboard instruments.Fretboard, units string, pointsPerUnit float64) {
+	page := d.newPage()
+	top := d.height - pdfMargin
+	page.text(pdfMargin, top-pdfTitleSize, pdfTitleSize, fmt.Sprintf("%s, scale length %s%s", fretboard.System, formatFloat(fretboard.ScaleLength), units))
+	page.text(pdfMargin, top-pdfTitleSize-pdfRowHeight, pdfFontSize, fretboard.Description)
+
+	square := defaultFretboardWidth(units)
+	squareSize := square * pointsPerUnit
+	page.lineWidth(0.5)
+	page.rectangle(d.width-pdfMargin-squareSize, top-squareSize, squareSize, squareSize)
+	page.text(d.width-pdfMargin-squareSize, top-squareSize-pdfRowHeight, pdfFontSize-2, fmt.Sprintf("Calibration: %s%s x %s%s at 100%%", formatFloat(square), units, formatFloat(square), units))
+
+	columns := []float64{pdfMargin, pdfMargin + 40, pdfMargin + 110, pdfMargin + 180, pdfMargin + 400}
+	header := func(page *pdfPage, y float64) {
+		for i, heading := range []string{"Fret", "Label", "Interval", "Comment", "Position (" + units + ")"} {
+			page.text(columns[i], y, pdfFontSize, heading)
+		}
+		page.line(pdfMargin, y-3, columns[4]+80, y-3)
+	}
+
+	y := top - pdfTitleSize - 3*pdfRowHeight
+	header(page, y)
+	for i, fret := range fretboard.Frets {
+		y -= pdfRowHeight
+		if y < pdfMargin {
+			page = d.newPage()
+			y = top - pdfRowHeight
+			header(page, y)
+			y -= pdfRowHeight
+		}
+		for c, value := range []string{fmt.Sprintf("%d", i), fret.Label, fret.Interval, fret.Comment, fmt.Sprintf(LengthFormat(units), fret.Position)} {
+			page.text(columns[c], y, pdfFontSize, value)
+		}
+	}
+}
+
+func renderPDFTemplate(d *pdfDocument, fretboard instruments.Fretboard, units string, taper taper, pointsPerUnit float64) {
+	lead := 10 * pointsPerInch / 25.4 / pointsPerUnit // 10mm either side of the nut and bridge
+	usableWidth := d.width - 2*pdfMargin
+	segment := usableWidth / pointsPerUnit
+	total := fretboard.ScaleLength + 2*lead
+	numberOfPages := int(math.Ceil(total / segment))
+	centre := d.height/2 + pdfRulerSpacing/2
+
+	for n := 0; n < numberOfPages; n++ {
+		page := d.newPage()
+		page.text(pdfMargin, d.height-pdfMargin-pdfFontSize, pdfFontSize, fmt.Sprintf("%s, scale length %s%s - template page %d of %d", fretboard.System, formatFloat(fretboard.ScaleLength), units, n+1, numberOfPages))
+
+		// each page shows the next segment of the template; x is in points from the left-hand end of the template
+		page.clipAndTranslate(pdfMargin, pdfMargin, usableWidth, d.height-2*pdfMargin-2*pdfRowHeight, pdfMargin-float64(n)*usableWidth)
+		x := func(position float64) float64 { return (lead + position) * pointsPerUnit }
+		y := func(offset float64) float64 { return centre + offset*pointsPerUnit }
+
+		page.lineWidth(1.5)
+		page.line(x(0), y(-taper.nutWidth/2), x(0), y(taper.nutWidth/2))
+		bridgeWidth := taper.widthAt(fretboard.ScaleLength)
+		page.line(x(fretboard.ScaleLength), y(-bridgeWidth/2), x(fretboard.ScaleLength), y(bridgeWidth/2))
+		page.lineWidth(0.3)
+		page.line(x(0), y(-taper.nutWidth/2), x(taper.length), y(-taper.heelWidth/2))
+		page.line(x(0), y(taper.nutWidth/2), x(taper.length), y(taper.heelWidth/2))
+		page.dashed(true)
+		page.line(x(0), centre, x(fretboard.ScaleLength), centre)
+		page.dashed(false)
+		for i, fret := range fretboard.Frets {
+			if fret.Position == 0 {
+				continue
+			}
+			halfWidth := taper.widthAt(fret.Position) / 2
+			page.line(x(fret.Position), y(-halfWidth), x(fret.Position), y(halfWidth))
+			page.text(x(fret.Position)-pdfFontSize/3, y(halfWidth)+4, pdfFontSize-2, fmt.Sprintf("%d", i))
+			page.rotatedText(x(fret.Position)-2, y(-halfWidth)+4, pdfFontSize-3, fret.Label)
+		}
+		renderPDFRuler(page, fretboard.ScaleLength, units, x, y(-math.Max(taper.nutWidth, bridgeWidth)/2)-pdfRulerSpacing)
+		page.restore()
+
+		page.lineWidth(0.3)
+		if n > 0 {
+			renderPDFAlignmentMark(page, pdfMargin, pdfMargin+3, d.height, fmt.Sprintf("align with page %d", n))
+		}
+		if n < numberOfPages-1 {
+			renderPDFAlignmentMark(page, d.width-pdfMargin, d.width-pdfMargin-45, d.height, fmt.Sprintf("align with page %d", n+2))
+		}
+	}
+}
+
+func renderPDFRuler(page *pdfPage, length float64, units string, x func(float64) float64, y float64) {
+	minorTick, ticksPerMajor := 1.0, 10
+	switch units {
+	case "in":
+		minorTick, ticksPerMajor = 0.125, 8
+	case "cm":
+		minorTick = 0.1
+	}
+	page.line(x(0), y, x(length), y)
+	for i := 0; float64(i)*minorTick <= length; i++ {
+		tick := 3.0
+		if i%(ticksPerMajor/2) == 0 {
+			tick = 6
+		}
+		if i%ticksPerMajor == 0 {
+			tick = 9
+			page.text(x(float64(i)*minorTick)-2, y-tick-pdfFontSize, pdfFontSize-2, fmt.Sprintf("%d", i/ticksPerMajor))
+		}
+		page.line(x(float64(i)*minorTick), y, x(float64(i)*minorTick), y-tick)
+	}
+}
+
+// renderPDFAlignmentMark draws a dashed join line with crosshairs at the edge of the printable area so adjacent
+// pages can be trimmed and overlaid exactly.
+func renderPDFAlignmentMark(page *pdfPage, x, labelX, height float64, label string) {
+	page.dashed(true)
+	page.line(x, pdfMargin, x, height-pdfMargin-2*pdfRowHeight)
+	page.dashed(false)
+	for _, y := range []float64{pdfMargin + 10, height - pdfMargin - 2*pdfRowHeight - 10} {
+		page.line(x-8, y, x+8, y)
+		page.line(x, y-8, x, y+8)
+		page.rectangle(x-4, y-4, 8, 8)
+	}
+	page.text(labelX, pdfMargin+22, pdfFontSize-3, label)
+}
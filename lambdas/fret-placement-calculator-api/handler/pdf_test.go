@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnPDFWithFretTableAndTemplateTiledAcrossPages(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "650", "tuningSystem": "equal", "divisions": "31", "octaves": "2", "format": "pdf"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/pdf", response.Headers["Content-Type"])
+	assert.True(t, response.IsBase64Encoded)
+
+	pdf, err := base64.StdEncoding.DecodeString(response.Body)
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assertCrossReferenceTableIsValid(t, pdf)
+
+	// two pages of table for 63 frets, then 670mm of template across three A4 pages
+	assert.Contains(t, string(pdf), "/Count 5")
+	assert.Contains(t, string(pdf), "(Calibration: 50mm x 50mm at 100%)")
+	assert.Contains(t, string(pdf), "(Position \\(mm\\))")
+	assert.Contains(t, string(pdf), "(38.71 cents)")
+	assert.Contains(t, string(pdf), "template page 3 of 3)")
+	assert.Contains(t, string(pdf), "(align with page 2)")
+}
+
+func Test_ShouldReturnLetterSizedPDFInInches(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "ptolemy", "format": "pdf", "paper": "letter"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	pdf, _ := base64.StdEncoding.DecodeString(response.Body)
+	assertCrossReferenceTableIsValid(t, pdf)
+	assert.Contains(t, string(pdf), "/MediaBox [0 0 792 612]")
+	assert.Contains(t, string(pdf), "(Calibration: 2in x 2in at 100%)")
+	assert.Contains(t, string(pdf), "144 144 re S")
+	assert.Contains(t, string(pdf), "/Count 4")
+}
+
+func Test_ShouldReturnErrorWhenPaperSizeIsNotSupported(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "650", "tuningSystem": "saz", "format": "pdf", "paper": "a3"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"not_allowed","parameter":"paper","reason":"must be one of the allowed values","allowedValues":["a4","letter"]}]}`}, response)
+}
+
+func assertCrossReferenceTableIsValid(t *testing.T, pdf []byte) {
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	assert.NotNil(t, startxref)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	assert.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1) {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
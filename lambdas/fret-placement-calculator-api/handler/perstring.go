@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"math"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// StringFret is where one fret lies under one string, moved from the equal-tempered fret line so that it sounds a pitch
+// of the tuning system.  Start and End are the ends of the fret segment, halfway to the neighbouring strings.
+type StringFret struct {
+	Fret     int     `json:"fret"`
+	Label    string  `json:"label"`
+	Position float64 `json:"position"`
+	Offset   float64 `json:"offset"`
+	Cents    float64 `json:"cents"`
+	Start    Point   `json:"start"`
+	End      Point   `json:"end"`
+}
+
+// FrettedString is one string of a fretboard whose frets are placed string by string.  Frets that would sound the same
+// pitch as another fret of the string are left out, making them partial frets.
+type FrettedString struct {
+	Number     int          `json:"number"`
+	OpenString string       `json:"openString"`
+	Nut        Point        `json:"nut"`
+	Bridge     Point        `json:"bridge"`
+	Frets      []StringFret `json:"frets"`
+}
+
+// PerStringFretboard is a fretboard with frets placed separately under each string, in the manner of True Temperament
+// necks, for tuning systems whose intervals depend on the key.  Coordinates are as for multiscale fretboards: x runs
+// along the centre line from the nut and y across it from the centre line, with the bass side negative.
+type PerStringFretboard struct {
+	System         string          `json:"system"`
+	Description    string          `json:"description"`
+	ScaleLength    float64         `json:"scaleLength"`
+	Units          string          `json:"units"`
+	Tonic          float64         `json:"tonic"`
+	FretsPerOctave int             `json:"fretsPerOctave"`
+	NutWidth       float64         `json:"nutWidth"`
+	BridgeWidth    float64         `json:"bridgeWidth"`
+	Strings        []FrettedString `json:"strings"`
+}
+
+var perStringParameters = []Parameter{
+	{Name: "openStrings", Type: PitchListParameter, Description: "Comma-separated pitches of the open strings from bass to treble, as note names and octaves (E2,A2,D3,G3,B3,E4) or frequencies in Hz", Required: true},
+	{Name: "tonic", Type: PitchParameter, Description: "Pitch from which the intervals of the tuning system are measured, as a note name and octave or a frequency in Hz (defaults to the first open string)"},
+	{Name: "fretsPerOctave", Type: IntegerParameter, Description: "Number of frets to the octave of the equal-tempered fret lines that the frets are moved from", Default: 12, Minimum: bound(1), Maximum: bound(72)},
+	{Name: "nutWidth", Type: NumberParameter, Description: "Distance between the outer strings at the nut", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "bridgeWidth", Type: NumberParameter, Description: "Distance between the outer strings at the bridge", Required: true, ExclusiveMinimum: bound(0)},
+	{Name: "referenceFrequency", Type: NumberParameter, Description: "Frequency of A4 in Hz", Default: defaultReferenceFrequency, ExclusiveMinimum: bound(0)},
+}
+
+func (h Handler) handlePerStringRequest(q map[string]string) events.LambdaFunctionURLResponse {
+	v := newValidator(q)
+	for _, p := range commonParameters() {
+		if p.Name != "format" && p.Name != "maximumPosition" {
+			v.parse(p)
+		}
+	}
+	system, _ := v.parseTuningSystem()
+	v.parse(perStringParameters...)
+	if v.args.has("openStrings") && len(v.args.pitches("openStrings")) < 2 {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "openStrings", Reason: "must give at least two strings"})
+	}
+	if v.valid() {
+		v.checkStringSpread(v.args.number("scaleLength"))
+	}
+	if !v.valid() {
+		return v.errorResponse()
+	}
+	return jsonResponse(newPerStringFretboard(v.args, system, strings.Split(v.q["openStrings"], ",")))
+}
+
+// targetScale is a tuning system's scale as sizes in cents above its tonic, with the frets of its degrees, repeating
+// at its period.
+type targetScale struct {
+	cents    []float64
+	frets    []scaleFret
+	period   float64
+	interval scaleInterval
+}
+
+func newTargetScale(period fretScale) targetScale {
+	ratios := period.ratios()
+	last := len(ratios) - 1
+	scale := targetScale{period: 1200 * math.Log2(ratios[last]), frets: period.frets[:last], interval: period.frets[last].pitch}
+	for _, ratio := range ratios[:last] {
+		scale.cents = append(scale.cents, 1200*math.Log2(ratio))
+	}
+	return scale
+}
+
+// nearest finds the degree of the scale, counted across periods from the tonic, nearest to a pitch in cents above the
+// tonic, returning the degree and its size in cents.
+func (s targetScale) nearest(cents float64) (int, float64) {
+	period := math.Floor(cents / s.period)
+	best, bestCents := 0, math.Inf(1)
+	for p := period - 1; p <= period+1; p++ {
+		for i, c := range s.cents {
+			if candidate := c + p*s.period; math.Abs(candidate-cents) < math.Abs(bestCents-cents) {
+				best, bestCents = i+int(p)*len(s.cents), candidate
+			}
+		}
+	}
+	return best, bestCents
+}
+
+// label names a degree of the scale as the interval it lies above the tonic, so that a degree a period up is labelled
+// as the period rather than as the tonic.
+func (s targetScale) label(degree int) string {
+	n := len(s.frets)
+	periods := int(math.Floor(float64(degree) / float64(n)))
+	fret := s.frets[degree-periods*n]
+	if periods == 0 {
+		return fret.label
+	}
+	return newScaleFret(fret.pitch.raisedBy(s.interval, periods), unison).label
+}
+
+func newPerStringFretboard(args arguments, system TuningSystem, openStrings []string) PerStringFretboard {
+	referenceFrequency := args.number("referenceFrequency")
+	pitches := args.pitches("openStrings")
+	tonic := pitches[0].hz(referenceFrequency)
+	if args.has("tonic") {
+		tonic = args.pitch("tonic").hz(referenceFrequency)
+	}
+
+	period := system.scale(1, args)
+	fretboard := PerStringFretboard{
+		System:         period.system,
+		Description:    period.description,
+		ScaleLength:    args.number("scaleLength"),
+		Units:          args.text("units"),
+		Tonic:          roundToHundredths(tonic),
+		FretsPerOctave: args.integer("fretsPerOctave"),
+		NutWidth:       args.number("nutWidth"),
+		BridgeWidth:    args.number("bridgeWidth"),
+	}
+
+	scale := newTargetScale(period)
+	length := fretboard.ScaleLength
+	numberOfStrings := len(pitches)
+	for i, openString := range pitches {
+		nutY := interpolate(-fretboard.NutWidth/2, fretboard.NutWidth/2, i, numberOfStrings)
+		bridgeY := interpolate(-fretboard.BridgeWidth/2, fretboard.BridgeWidth/2, i, numberOfStrings)
+		span := math.Sqrt(length*length - (bridgeY-nutY)*(bridgeY-nutY))
+		s := FrettedString{
+			Number:     i + 1,
+			OpenString: strings.TrimSpace(openStrings[i]),
+			Nut:        Point{X: 0, Y: roundToPrecisionOf(nutY, fretboard.Units)},
+			Bridge:     Point{X: roundToPrecisionOf(span, fretboard.Units), Y: roundToPrecisionOf(bridgeY, fretboard.Units)},
+		}
+
+		open := 1200 * math.Log2(openString.hz(referenceFrequency)/tonic)
+		degrees := map[int]int{} // index into s.Frets of the fret sounding each degree of the scale
+		for fret := 1; fret <= args.integer("octaves")*fretboard.FretsPerOctave; fret++ {
+			equal := 1200 * float64(fret) / float64(fretboard.FretsPerOctave)
+			degree, target := scale.nearest(open + equal)
+			cents := target - open
+			if cents <= 0 {
+				continue
+			}
+			position := length * (1 - math.Pow(2, -cents/1200))
+			halfSpacing := (fretboard.NutWidth + (fretboard.BridgeWidth-fretboard.NutWidth)*position/length) / float64(numberOfStrings-1) / 2
+			x, y := span*position/length, nutY+(bridgeY-nutY)*position/length
+			stringFret := StringFret{
+				Fret:     fret,
+				Label:    scale.label(degree),
+				Position: roundToPrecisionOf(position, fretboard.Units),
+				Offset:   roundToPrecisionOf(position-length*(1-math.Pow(2, -equal/1200)), fretboard.Units),
+				Cents:    roundToHundredths(cents),
+				Start:    Point{X: roundToPrecisionOf(x, fretboard.Units), Y: roundToPrecisionOf(y-halfSpacing, fretboard.Units)},
+				End:      Point{X: roundToPrecisionOf(x, fretboard.Units), Y: roundToPrecisionOf(y+halfSpacing, fretboard.Units)},
+			}
+
+			// two frets sounding the same degree would be redundant, so only the one nearer its fret line is kept
+			if j, taken := degrees[degree]; taken {
+				if math.Abs(stringFret.Offset) < math.Abs(s.Frets[j].Offset) {
+					s.Frets[j] = stringFret
+				}
+				continue
+			}
+			degrees[degree] = len(s.Frets)
+			s.Frets = append(s.Frets, stringFret)
+		}
+		fretboard.Strings = append(fretboard.Strings, s)
+	}
+	return fretboard
+}
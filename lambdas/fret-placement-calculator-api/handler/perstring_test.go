@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func perStringRequest(q map[string]string) events.LambdaFunctionURLRequest {
+	return events.LambdaFunctionURLRequest{RawPath: "/perStringFrets", QueryStringParameters: q}
+}
+
+func Test_ShouldLeaveEqualTemperedFretsOnTheirFretLines(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "648", "tuningSystem": "equal", "divisions": "12", "openStrings": "E2,A2", "nutWidth": "10", "bridgeWidth": "20"}
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), perStringRequest(q))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var fretboard PerStringFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 2, len(fretboard.Strings))
+	for _, s := range fretboard.Strings {
+		assert.Equal(t, 12, len(s.Frets))
+		for _, fret := range s.Frets {
+			assert.Equal(t, 0.0, fret.Offset)
+		}
+	}
+	assert.Equal(t, Point{X: 0, Y: -5}, fretboard.Strings[0].Nut)
+	assert.Equal(t, StringFret{Fret: 1, Label: "100.00 cents", Position: 36.37, Cents: 100, Start: Point{X: 36.37, Y: -10.56}, End: Point{X: 36.37, Y: 0}}, fretboard.Strings[0].Frets[0])
+	assert.Equal(t, "600.00 cents", fretboard.Strings[1].Frets[0].Label)
+}
+
+func Test_ShouldMoveFretsOfEachStringToSoundJustIntervalsAboveTheTonic(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "648", "tuningSystem": "justFromRatios", "openStrings": "E2,A2,D3,G3,B3,E4", "nutWidth": "43", "bridgeWidth": "52"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), perStringRequest(q))
+
+	// Then
+	var fretboard PerStringFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 82.41, fretboard.Tonic)
+	assert.Equal(t, 6, len(fretboard.Strings))
+	low := fretboard.Strings[0]
+	assert.Equal(t, StringFret{Fret: 4, Label: "5:4", Position: 129.6, Offset: -4.08, Cents: 386.31, Start: Point{X: 129.6, Y: -26.88}, End: Point{X: 129.6, Y: -17.92}}, low.Frets[3])
+	assert.Equal(t, StringFret{Fret: 7, Label: "3:2", Position: 216, Offset: 0.49, Cents: 701.96, Start: Point{X: 215.99, Y: -27.6}, End: Point{X: 215.99, Y: -18.4}}, low.Frets[6])
+	assert.Equal(t, "45:32", fretboard.Strings[1].Frets[0].Label)
+	assert.Equal(t, -3.46, fretboard.Strings[1].Frets[0].Offset)
+}
+
+func Test_ShouldLeaveOutFretsThatWouldRepeatANoteOfTheString(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "648", "tuningSystem": "ptolemy", "openStrings": "E2,A2", "tonic": "A2", "nutWidth": "10", "bridgeWidth": "20"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), perStringRequest(q))
+
+	// Then
+	var fretboard PerStringFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	var frets []int
+	for _, fret := range fretboard.Strings[0].Frets {
+		frets = append(frets, fret.Fret)
+	}
+	assert.Equal(t, []int{2, 4, 5, 7, 9, 10, 12}, frets)
+	assert.Equal(t, StringFret{Fret: 5, Label: "1:1", Position: 162.55, Cents: 500, Start: Point{X: 162.54, Y: -12.51}, End: Point{X: 162.54, Y: 0}}, fretboard.Strings[0].Frets[2])
+}
+
+func Test_ShouldReturnErrorsWhenPerStringFretsAreInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "single string",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "meantone", "openStrings": "E2", "nutWidth": "10", "bridgeWidth": "20"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"openStrings","reason":"must give at least two strings"}]}`,
+		},
+		{
+			name:  "strings spread wider than they are long",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "meantone", "openStrings": "E2,A2", "nutWidth": "10", "bridgeWidth": "2000"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"bridgeWidth","reason":"must differ from nutWidth by less than twice the scale length"}]}`,
+		},
+		{
+			name:  "missing and invalid parameters",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "meantone", "openStrings": "E2,A2", "tonic": "H", "fretsPerOctave": "0"},
+			body:  `{"errors":[{"code":"invalid_type","parameter":"tonic","reason":"must be a note name and octave such as E2, F#3 or Bb1, or a frequency in Hz"},{"code":"out_of_range","parameter":"fretsPerOctave","reason":"must be between 1 and 72"},{"code":"required","parameter":"nutWidth","reason":"is required"},{"code":"required","parameter":"bridgeWidth","reason":"is required"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), perStringRequest(tt.query))
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
+
+func Test_ShouldGivePerStringFretsToThousandthsInInches(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "25.5", "tuningSystem": "justFromRatios", "openStrings": "E2,A2", "nutWidth": "1.7", "bridgeWidth": "2.1", "units": "in"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), perStringRequest(q))
+
+	// Then
+	var fretboard PerStringFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, StringFret{Fret: 1, Label: "16:15", Position: 1.594, Offset: 0.163, Cents: 111.73, Start: Point{X: 1.594, Y: -1.725}, End: Point{X: 1.594, Y: 0}}, fretboard.Strings[0].Frets[0])
+}
+
+func Test_ShouldLabelFretsWithTheWholeIntervalAboveTheTonic(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		want  map[string]string
+	}{
+		{
+			name:  "just frets an octave up",
+			query: map[string]string{"tuningSystem": "justFromRatios", "openStrings": "E2,D3"},
+			want:  map[string]string{"E2 fret 12": "2:1", "E2 fret 24": "4:1", "D3 fret 2": "2:1"},
+		},
+		{
+			name:  "tempered frets an octave up",
+			query: map[string]string{"tuningSystem": "equal", "divisions": "12", "openStrings": "E2,D3"},
+			want:  map[string]string{"E2 fret 12": "1200.00 cents", "E2 fret 24": "2400.00 cents", "D3 fret 2": "1200.00 cents"},
+		},
+		{
+			name:  "frets below the tonic",
+			query: map[string]string{"tuningSystem": "justFromRatios", "openStrings": "E2,A2", "tonic": "A2"},
+			want:  map[string]string{"E2 fret 1": "4:5", "E2 fret 5": "1:1", "A2 fret 12": "2:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			tt.query["scaleLength"], tt.query["nutWidth"], tt.query["bridgeWidth"], tt.query["octaves"] = "648", "43", "52", "2"
+
+			// When
+			response, _ := Handler{}.HandleRequest(context.Background(), perStringRequest(tt.query))
+
+			// Then
+			var fretboard PerStringFretboard
+			_ = json.Unmarshal([]byte(response.Body), &fretboard)
+			labels := map[string]string{}
+			for _, s := range fretboard.Strings {
+				for _, fret := range s.Frets {
+					labels[fmt.Sprintf("%s fret %d", s.OpenString, fret.Fret)] = fret.Label
+				}
+			}
+			for fret, label := range tt.want {
+				assert.Equal(t, label, labels[fret], fret)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"math"
+)
+
+// FretPitch gives the pitch a fret sounds, given that of the open string.
+type FretPitch struct {
+	Fret        int     `json:"fret"`
+	Frequency   float64 `json:"frequency"`
+	Cents       float64 `json:"cents"`
+	NearestNote string  `json:"nearestNote"`
+	Deviation   float64 `json:"deviation"`
+}
+
+var pitchParameters = []Parameter{
+	{Name: "openString", Type: PitchParameter, Description: "Pitch of the open string, as a note name and octave (E2, F#3, Bb1) or a frequency in Hz", Required: true},
+	{Name: "referenceFrequency", Type: NumberParameter, Description: "Frequency of A4 in Hz", Default: defaultReferenceFrequency, ExclusiveMinimum: bound(0)},
+}
+
+func newFretPitches(openString, referenceFrequency float64, ratios []float64) []FretPitch {
+	var pitches []FretPitch
+	for i, ratio := range ratios {
+		frequency := openString * ratio
+		note, deviation := nearestNote(frequency, referenceFrequency)
+		pitches = append(pitches, FretPitch{
+			Fret:        i,
+			Frequency:   roundToHundredths(frequency),
+			Cents:       roundToHundredths(1200 * math.Log2(ratio)),
+			NearestNote: note,
+			Deviation:   roundToHundredths(deviation),
+		})
+	}
+	return pitches
+}
+
+func fretboardPitches(args arguments, scale fretScale) []FretPitch {
+	referenceFrequency := args.number("referenceFrequency")
+	return newFretPitches(args.pitch("openString").hz(referenceFrequency), referenceFrequency, scale.ratios())
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnThePitchOfEveryFretGivenTheOpenString(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "ptolemy", "openString": "E2"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, len(fretboard.Frets), len(fretboard.Pitches))
+	assert.Equal(t, FretPitch{Fret: 0, Frequency: 82.41, Cents: 0, NearestNote: "E2", Deviation: 0}, fretboard.Pitches[0])
+	assert.Equal(t, FretPitch{Fret: 2, Frequency: 103.01, Cents: 386.31, NearestNote: "G#2", Deviation: -13.69}, fretboard.Pitches[2])
+	assert.Equal(t, FretPitch{Fret: 7, Frequency: 164.81, Cents: 1200, NearestNote: "E3", Deviation: 0}, fretboard.Pitches[7])
+}
+
+func Test_ShouldMeasurePitchesAgainstTheReferenceFrequency(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "meantone", "octaves": "2", "openString": "110Hz", "referenceFrequency": "415"},
+	})
+
+	// Then
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 27, len(fretboard.Pitches))
+	assert.Equal(t, FretPitch{Fret: 0, Frequency: 110, Cents: 0, NearestNote: "A#2", Deviation: 1.27}, fretboard.Pitches[0])
+	assert.Equal(t, FretPitch{Fret: 26, Frequency: 440, Cents: 2400, NearestNote: "A#4", Deviation: 1.27}, fretboard.Pitches[26])
+}
+
+func Test_ShouldReturnErrorIfOpenStringPitchIsInvalid(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "648", "tuningSystem": "saz", "openString": "low E", "referenceFrequency": "-440"},
+	})
+
+	// Then
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assert.Equal(t, `{"errors":[{"code":"invalid_type","parameter":"openString","reason":"must be a note name and octave such as E2, F#3 or Bb1, or a frequency in Hz"},{"code":"out_of_range","parameter":"referenceFrequency","reason":"must be greater than 0"}]}`, response.Body)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/mikebharris/music/music"
+)
+
+const (
+	defaultGeneratorsUp   = 11
+	defaultGeneratorsDown = 0
+)
+
+// regularTemperament is a well-known temperament, given by the size in cents of its generator within the octave and
+// the number of generators stacked up and down from the open string to make its usual scale, of one note more than
+// there are generators.
+type regularTemperament struct {
+	id             string
+	name           string
+	generator      float64
+	generatorsUp   int
+	generatorsDown int
+}
+
+// meantoneFifth is a perfect fifth narrowed by a fraction of a syntonic comma.
+func meantoneFifth(fractionOfComma float64) float64 {
+	return 1200*math.Log2(3.0/2) - 1200*math.Log2(81.0/80)*fractionOfComma
+}
+
+var regularTemperaments = []regularTemperament{
+	{id: "quarterCommaMeantone", name: "quarter-comma meantone", generator: meantoneFifth(1.0 / 4), generatorsUp: 6, generatorsDown: 5},
+	{id: "thirdCommaMeantone", name: "1/3-comma meantone", generator: meantoneFifth(1.0 / 3), generatorsUp: 6, generatorsDown: 5},
+	{id: "sixthCommaMeantone", name: "1/6-comma meantone", generator: meantoneFifth(1.0 / 6), generatorsUp: 6, generatorsDown: 5},
+	{id: "porcupine", name: "Porcupine", generator: 163.950, generatorsUp: 7, generatorsDown: 7},
+	{id: "magic", name: "Magic", generator: 380.352, generatorsUp: 9, generatorsDown: 9},
+	{id: "miracle", name: "Miracle", generator: 116.716, generatorsUp: 10, generatorsDown: 10},
+	{id: "schismatic", name: "Schismatic", generator: 701.711, generatorsUp: 6, generatorsDown: 5},
+}
+
+func regularTemperamentIDs() []string {
+	var ids []string
+	for _, t := range regularTemperaments {
+		ids = append(ids, t.id)
+	}
+	return ids
+}
+
+// lookupRegularTemperament finds the chosen temperament, or otherwise one whose generator is given by the request.
+func lookupRegularTemperament(args arguments) regularTemperament {
+	for _, t := range regularTemperaments {
+		if args.has("temperament") && t.id == args.text("temperament") {
+			return t
+		}
+	}
+	return regularTemperament{name: "a regular", generatorsUp: defaultGeneratorsUp, generatorsDown: defaultGeneratorsDown}
+}
+
+var regularParameters = []Parameter{
+	{Name: "temperament", Type: StringParameter, Description: "Well-known temperament supplying the generator and the numbers of generators up and down", AllowedValues: regularTemperamentIDs()},
+	{Name: "generator", Type: IntervalParameter, Description: "Interval stacked up and down from the open string to make the scale, as a ratio or in cents; needed unless a temperament is chosen, whose generator it replaces"},
+	{Name: "period", Type: IntervalParameter, Description: "Interval at which the scale repeats, as a ratio or in cents", Default: scaleInterval{ratio: music.Octave(), just: true}},
+	{Name: "generatorsUp", Type: IntegerParameter, Description: "Number of generators stacked above the open string (defaults to that of the temperament, or 11)", Minimum: bound(0), Maximum: bound(100)},
+	{Name: "generatorsDown", Type: IntegerParameter, Description: "Number of generators stacked below the open string (defaults to that of the temperament, or 0)", Minimum: bound(0), Maximum: bound(100)},
+}
+
+func validateRegularTemperament(args arguments) *ValidationError {
+	if !args.has("generator") {
+		if !args.has("temperament") {
+			return &ValidationError{Code: RequiredError, Parameter: "generator", Reason: "is required unless a temperament is chosen"}
+		}
+		if temperament, period := lookupRegularTemperament(args), args.interval("period"); temperament.generator >= period.toCents() {
+			return &ValidationError{Code: OutOfRangeError, Parameter: "period", Reason: fmt.Sprintf("must be larger than the %.2f-cent generator of %s", temperament.generator, temperament.id)}
+		}
+		return nil
+	}
+	if period := args.interval("period"); args.interval("generator").toCents() >= period.toCents() {
+		return &ValidationError{Code: OutOfRangeError, Parameter: "generator", Reason: fmt.Sprintf("must be smaller than the period of %s", period)}
+	}
+	return nil
+}
+
+// newRegularTemperamentScale stacks the generator up and down from the open string, bringing each step back within
+// the period, so that the scale is a chain of generators such as the chain of fifths of meantone.  Generators are
+// stacked in cents, as long chains of just ratios soon grow too large to hold.
+func newRegularTemperamentScale(periods int, args arguments) fretScale {
+	temperament := lookupRegularTemperament(args)
+	generator := temperament.generator
+	if args.has("generator") {
+		generator = args.interval("generator").toCents()
+	}
+	up, down := temperament.generatorsUp, temperament.generatorsDown
+	if args.has("generatorsUp") {
+		up = args.integer("generatorsUp")
+	}
+	if args.has("generatorsDown") {
+		down = args.integer("generatorsDown")
+	}
+
+	period := args.interval("period")
+	steps := generatorChain(generator, period.toCents(), up, down)
+	var intervals []scaleInterval
+	for _, cents := range steps {
+		intervals = append(intervals, scaleInterval{cents: cents})
+	}
+	description := fmt.Sprintf("Fret positions based on %s temperament, %d notes generated by %.2f cents (%d up, %d down) repeating at %s.",
+		temperament.name, len(steps)+1, generator, up, down, period)
+	return newScaleFromIntervals("Regular Temperament", description, periods, intervals, period)
+}
+
+// generatorChain gives the ascending sizes in cents, within the period and excluding the unison, of the notes reached
+// by stacking the generator up and down.  A generator that divides the period evenly reaches the same notes more than
+// once, and those are only given once.
+func generatorChain(generator, period float64, up, down int) []float64 {
+	var steps []float64
+	for k := -down; k <= up; k++ {
+		cents := math.Mod(float64(k)*generator, period)
+		if cents < 0 {
+			cents += period
+		}
+		if cents = math.Round(cents*1e9) / 1e9; cents > 0 && cents < period && !slices.Contains(steps, cents) {
+			steps = append(steps, cents)
+		}
+	}
+	slices.Sort(steps)
+	return steps
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_generatorChainShouldBringEveryStepWithinThePeriod(t *testing.T) {
+	// Given
+	// When
+	steps := generatorChain(700, 1200, 2, 1)
+
+	// Then
+	assert.Equal(t, []float64{200, 500, 700}, steps)
+}
+
+func Test_generatorChainShouldGiveNotesReachedMoreThanOnceOnlyOnce(t *testing.T) {
+	// Given
+	// When
+	steps := generatorChain(700, 1200, 14, 0)
+
+	// Then
+	assert.Equal(t, 11, len(steps))
+	assert.Equal(t, 100.0, steps[0])
+}
+
+func Test_ShouldMatchTheMeantoneTuningSystemForQuarterCommaMeantone(t *testing.T) {
+	// Given
+	request := func(q map[string]string) instruments.Fretboard {
+		response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+		fretboard := instruments.Fretboard{}
+		_ = json.Unmarshal([]byte(response.Body), &fretboard)
+		return fretboard
+	}
+
+	// When
+	regular := request(map[string]string{"scaleLength": "600", "tuningSystem": "regular", "temperament": "quarterCommaMeantone"})
+	meantone := request(map[string]string{"scaleLength": "600", "tuningSystem": "meantone"})
+
+	// Then
+	assert.Equal(t, "Regular Temperament", regular.System)
+	assert.Equal(t, "Fret positions based on quarter-comma meantone temperament, 12 notes generated by 696.58 cents (6 up, 5 down) repeating at 2:1.", regular.Description)
+	// the music module's meantone has both an augmented fourth and a diminished fifth, six fifths up and down, where the
+	// usual twelve notes stop at five fifths down
+	meantone.Frets = slices.Delete(meantone.Frets, 7, 8)
+	assert.Equal(t, len(meantone.Frets), len(regular.Frets))
+	for i := range meantone.Frets {
+		// the music module rounds its meantone ratios to three decimal places
+		assert.InDelta(t, meantone.Frets[i].Position, regular.Frets[i].Position, 0.2)
+	}
+}
+
+func Test_ShouldStackAGivenGeneratorWithinAGivenPeriod(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "600", "tuningSystem": "regular", "generator": "5:3", "period": "3:1", "generatorsUp": "4", "octaves": "2"}
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	fretboard := instruments.Fretboard{}
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Fret positions based on a regular temperament, 5 notes generated by 884.36 cents (4 up, 0 down) repeating at 3:1.", fretboard.Description)
+	assert.Equal(t, 11, len(fretboard.Frets))
+	assert.Equal(t, instruments.Fret{Label: "884.36 cents", Position: 240}, fretboard.Frets[2])
+	assert.Equal(t, instruments.Fret{Label: "3:1", Position: 400}, fretboard.Frets[5])
+}
+
+func Test_ShouldOverrideTheDefaultsOfATemperament(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "600", "tuningSystem": "regular", "temperament": "porcupine", "generatorsUp": "3", "generatorsDown": "3"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+
+	// Then
+	fretboard := instruments.Fretboard{}
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Fret positions based on Porcupine temperament, 7 notes generated by 163.95 cents (3 up, 3 down) repeating at 2:1.", fretboard.Description)
+	assert.Equal(t, 8, len(fretboard.Frets))
+}
+
+func Test_ShouldReturnErrorIfRegularTemperamentIsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "neither generator nor temperament",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "regular"},
+			body:  `{"errors":[{"code":"required","parameter":"generator","reason":"is required unless a temperament is chosen"}]}`,
+		},
+		{
+			name:  "unknown temperament",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "regular", "temperament": "mavila"},
+			body:  `{"errors":[{"code":"not_allowed","parameter":"temperament","reason":"must be one of the allowed values","allowedValues":["quarterCommaMeantone","thirdCommaMeantone","sixthCommaMeantone","porcupine","magic","miracle","schismatic"]}]}`,
+		},
+		{
+			name:  "generator beyond the period",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "regular", "generator": "2000", "period": "3:1"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"generator","reason":"must be smaller than the period of 3:1"}]}`,
+		},
+		{
+			name:  "period within the generator of the temperament",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "regular", "temperament": "magic", "period": "100"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"period","reason":"must be larger than the 380.35-cent generator of magic"}]}`,
+		},
+		{
+			name:  "too many generators",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "regular", "generator": "700", "generatorsUp": "101"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"generatorsUp","reason":"must be between 0 and 100"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+)
+
+// scalaScale is a scale read from a Scala (.scl) file, as described at https://www.huygens-fokker.org/scala/scl_format.html
+type scalaScale struct {
+	description string
+	intervals   []scaleInterval
+	comments    []string
+}
+
+func parseScalaFile(text string) (scalaScale, error) {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if !strings.HasPrefix(line, "!") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	if len(lines) < 2 {
+		return scalaScale{}, errors.New("the description and number of notes are missing")
+	}
+
+	scale := scalaScale{description: lines[0]}
+	fields := strings.Fields(lines[1])
+	if len(fields) == 0 {
+		return scalaScale{}, errors.New("the number of notes is missing")
+	}
+	numberOfNotes, err := strconv.Atoi(fields[0])
+	if err != nil || numberOfNotes < 1 {
+		return scalaScale{}, fmt.Errorf("the number of notes must be a positive integer, not %s", fields[0])
+	}
+
+	for _, line := range lines[2:] {
+		if len(scale.intervals) == numberOfNotes {
+			break
+		}
+		if line == "" {
+			continue
+		}
+		pitch := strings.Fields(line)[0]
+		interval, err := parseScalaPitch(pitch)
+		if err != nil {
+			return scalaScale{}, fmt.Errorf("pitch %d (%s) is neither a ratio nor a value in cents", len(scale.intervals)+1, pitch)
+		}
+		scale.intervals = append(scale.intervals, interval)
+		scale.comments = append(scale.comments, strings.TrimSpace(strings.TrimPrefix(line, pitch)))
+	}
+	if len(scale.intervals) < numberOfNotes {
+		return scalaScale{}, fmt.Errorf("%d notes were declared but only %d pitches given", numberOfNotes, len(scale.intervals))
+	}
+	return scale, nil
+}
+
+// parseScalaPitch follows Scala in reading values with a decimal point as cents and anything else as a ratio, with a
+// bare integer n meaning n/1.
+func parseScalaPitch(s string) (scaleInterval, error) {
+	if strings.Contains(s, ".") {
+		cents, err := parseFiniteFloat(s)
+		if err != nil {
+			return scaleInterval{}, err
+		}
+		return scaleInterval{cents: cents}, nil
+	}
+	if !strings.Contains(s, "/") {
+		s += "/1"
+	}
+	return parseScaleInterval(s)
+}
+
+// period is the last pitch of a Scala scale, at which it repeats.
+func (s scalaScale) period() scaleInterval {
+	return s.intervals[len(s.intervals)-1]
+}
+
+func (s scalaScale) scale(periods int) fretScale {
+	description := s.description
+	if description == "" {
+		description = fmt.Sprintf("Fret positions based on a Scala scale of %d notes.", len(s.intervals))
+	}
+	steps := s.intervals[:len(s.intervals)-1]
+	scale := newScaleFromIntervals("Scala", description, periods, steps, s.period())
+	for i := range scale.frets[1:] {
+		if comment := s.comments[i%len(s.comments)]; comment != "" {
+			scale.frets[i+1].comment = comment
+		}
+	}
+	return scale
+}
+
+// scalaFileFrom reads the Scala file sent as the body of a request, either on its own or as a multipart form upload.
+// isScalaRequest tells whether the body of a request could be a Scala file: sent as plain text, uploaded as a form, or
+// sent with no content type at all.
+func isScalaRequest(request events.LambdaFunctionURLRequest) bool {
+	mediaType, _, _ := mime.ParseMediaType(request.Headers["content-type"])
+	return mediaType == "" || mediaType == "text/plain" || mediaType == "multipart/form-data"
+}
+
+func scalaFileFrom(request events.LambdaFunctionURLRequest) (string, error) {
+	body, err := bodyOf(request)
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(request.Headers["content-type"])
+	if mediaType != "multipart/form-data" {
+		return string(body), nil
+	}
+	part, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).NextPart()
+	if err != nil {
+		return "", err
+	}
+	file, err := io.ReadAll(part)
+	return string(file), err
+}
+
+var kbmParameters = []Parameter{
+	{Name: "middleNote", Type: IntegerParameter, Description: "MIDI note to which the open string (the first degree of the scale) is mapped", Default: 60, Minimum: bound(0), Maximum: bound(127)},
+	{Name: "referenceNote", Type: IntegerParameter, Description: "MIDI note whose frequency is given", Default: 69, Minimum: bound(0), Maximum: bound(127)},
+	{Name: "referenceFrequency", Type: NumberParameter, Description: "Frequency of the reference note in Hz", Default: defaultReferenceFrequency, ExclusiveMinimum: bound(0)},
+}
+
+// renderSCL writes a single period of the fretboard, from the first fret to the period, as a Scala scale file.
+func renderSCL(fretboard instruments.Fretboard, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "! %s.scl\n!\n", name)
+	fmt.Fprintf(&b, "%s\n", strings.TrimPrefix(fretboard.Description, "Fret positions based on "))
+	fmt.Fprintf(&b, " %d\n!\n", len(fretboard.Frets)-1)
+	for _, fret := range fretboard.Frets[1:] {
+		pitch := strings.Replace(fret.Label, ":", "/", 1)
+		if cents, ok := strings.CutSuffix(fret.Label, " cents"); ok {
+			pitch = cents
+		}
+		fmt.Fprintf(&b, " %s", pitch)
+		if fret.Comment != "" {
+			fmt.Fprintf(&b, " %s", fret.Comment)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderKBM writes a linear Scala keyboard mapping with one key for each degree of the fretboard's scale.
+func renderKBM(fretboard instruments.Fretboard, name string, args arguments) string {
+	degrees := len(fretboard.Frets) - 1
+	var b strings.Builder
+	fmt.Fprintf(&b, "! %s.kbm\n!\n", name)
+	fmt.Fprintf(&b, "! Size of map:\n%d\n", degrees)
+	b.WriteString("! First MIDI note number to retune:\n0\n")
+	b.WriteString("! Last MIDI note number to retune:\n127\n")
+	fmt.Fprintf(&b, "! Middle note where the first entry of the mapping is mapped to:\n%d\n", args.integer("middleNote"))
+	fmt.Fprintf(&b, "! Reference note for which frequency is given:\n%d\n", args.integer("referenceNote"))
+	fmt.Fprintf(&b, "! Frequency to tune the above note to:\n%f\n", args.number("referenceFrequency"))
+	fmt.Fprintf(&b, "! Scale degree to consider as formal octave:\n%d\n", degrees)
+	b.WriteString("! Mapping.\n")
+	for degree := range degrees {
+		fmt.Fprintf(&b, "%d\n", degree)
+	}
+	return b.String()
+}
+
+func (p Parameter) parseScala(raw string) (any, *ValidationError) {
+	scale, err := parseScalaFile(raw)
+	if err != nil {
+		return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a Scala scale file: " + err.Error()}
+	}
+	previous := unison
+	for _, interval := range scale.intervals {
+		if interval.toCents() <= previous.toCents() {
+			return nil, &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: "pitches must be in ascending order and each larger than 1/1"}
+		}
+		if interval.toCents() > maximumInterval {
+			return nil, &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: fmt.Sprintf("pitches must each be no larger than %d cents (%d/1)", maximumInterval, 1<<(maximumInterval/1200))}
+		}
+		previous = interval
+	}
+	return scale, nil
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mikebharris/music/instruments"
+	"github.com/mikebharris/music/music"
+	"github.com/stretchr/testify/assert"
+)
+
+const bohlenPierce = `! bohlen-pierce.scl
+!
+Bohlen-Pierce scale, just version
+ 13
+!
+ 27/25           just minor second
+ 25/21
+ 9/7
+ 7/5
+ 75/49
+ 5/3
+ 9/5
+ 49/25
+ 15/7
+ 7/3
+ 63/25
+ 25/9
+ 3             tritave
+`
+
+func Test_parseScalaFile(t *testing.T) {
+	// Given
+	text := "! meantone.scl\r\n!\r\nQuarter-comma meantone, fifths only\r\n3\r\n!\r\n696.578 fifth\r\n   1003.422\r\n2/1 octave\r\n"
+
+	// When
+	scale, err := parseScalaFile(text)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "Quarter-comma meantone, fifths only", scale.description)
+	assert.Equal(t, []scaleInterval{{cents: 696.578}, {cents: 1003.422}, {ratio: music.Octave(), just: true}}, scale.intervals)
+	assert.Equal(t, []string{"fifth", "", "octave"}, scale.comments)
+	assert.Equal(t, scaleInterval{ratio: music.Octave(), just: true}, scale.period())
+}
+
+func Test_parseScalaFileShouldReadBareIntegersAsRatios(t *testing.T) {
+	// Given
+	// When
+	scale, err := parseScalaFile(bohlenPierce)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 13, len(scale.intervals))
+	assert.Equal(t, scaleInterval{ratio: music.NewInterval(3, 1), just: true}, scale.period())
+	assert.Equal(t, "just minor second", scale.comments[0])
+}
+
+func Test_parseScalaFileShouldRejectMalformedFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "empty", text: "", want: "the description and number of notes are missing"},
+		{name: "no count", text: "Empty\n\n", want: "the number of notes is missing"},
+		{name: "bad count", text: "Scale\nfive\n", want: "the number of notes must be a positive integer, not five"},
+		{name: "too few pitches", text: "Scale\n3\n9/8\n2/1\n", want: "3 notes were declared but only 2 pitches given"},
+		{name: "bad pitch", text: "Scale\n2\n9/8\nfifth\n", want: "pitch 2 (fifth) is neither a ratio nor a value in cents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseScalaFile(tt.text)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func Test_ShouldReturnFretPlacementsForPostedScalaFile(t *testing.T) {
+	// Given
+	request := events.LambdaFunctionURLRequest{
+		RequestContext:        events.LambdaFunctionURLRequestContext{HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodPost}},
+		Headers:               map[string]string{"content-type": "text/plain"},
+		QueryStringParameters: map[string]string{"scaleLength": "600"},
+		Body:                  bohlenPierce,
+	}
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), request)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	fretboard := instruments.Fretboard{}
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Scala", fretboard.System)
+	assert.Equal(t, "Bohlen-Pierce scale, just version", fretboard.Description)
+	assert.Equal(t, 14, len(fretboard.Frets))
+	assert.Equal(t, instruments.Fret{Label: "27:25", Position: 44.44, Comment: "just minor second", Interval: "27:25"}, fretboard.Frets[1])
+	assert.Equal(t, instruments.Fret{Label: "5:3", Position: 240, Comment: "Major Sixth", Interval: "49:45"}, fretboard.Frets[6])
+	assert.Equal(t, instruments.Fret{Label: "3:1", Position: 400, Comment: "tritave", Interval: "27:25"}, fretboard.Frets[13])
+}
+
+func Test_ShouldAcceptScalaFilesUploadedAsMultipartForms(t *testing.T) {
+	// Given
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "bohlen-pierce.scl")
+	_, _ = file.Write([]byte(bohlenPierce))
+	_ = form.Close()
+
+	request := events.LambdaFunctionURLRequest{
+		RequestContext:        events.LambdaFunctionURLRequestContext{HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodPost}},
+		Headers:               map[string]string{"content-type": form.FormDataContentType()},
+		QueryStringParameters: map[string]string{"scaleLength": "600", "octaves": "2", "format": "csv"},
+		Body:                  base64.StdEncoding.EncodeToString(body.Bytes()),
+		IsBase64Encoded:       true,
+	}
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), request)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Body, "14,81:25,")
+	assert.Contains(t, response.Body, ",just minor second\n")
+	assert.Contains(t, response.Body, "26,9:1,")
+}
+
+func Test_ShouldAcceptScalaFilesAsAQueryParameter(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "scala", "scl": "\n2\n600.0\n1200.0\n"},
+	})
+
+	// Then
+	fretboard := instruments.Fretboard{}
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "Fret positions based on a Scala scale of 2 notes.", fretboard.Description)
+	assert.Equal(t, []instruments.Fret{{Label: "0.00 cents"}, {Label: "600.00 cents", Position: 175.74}, {Label: "1200.00 cents", Position: 300}}, fretboard.Frets)
+}
+
+func Test_ShouldReturnErrorIfScalaFileIsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "missing pitches",
+			body: "Scale\n3\n9/8\n2/1\n",
+			want: `{"errors":[{"code":"invalid_type","parameter":"scl","reason":"must be a Scala scale file: 3 notes were declared but only 2 pitches given"}]}`,
+		},
+		{
+			name: "descending pitches",
+			body: "Scale\n3\n5/4\n9/8\n2/1\n",
+			want: `{"errors":[{"code":"out_of_range","parameter":"scl","reason":"pitches must be in ascending order and each larger than 1/1"}]}`,
+		},
+		{
+			name: "pitches reaching the bridge",
+			body: "Scale\n2\n9/8\n100000.0\n",
+			want: `{"errors":[{"code":"out_of_range","parameter":"scl","reason":"pitches must each be no larger than 4800 cents (16/1)"}]}`,
+		},
+		{
+			name: "empty body",
+			body: "",
+			want: `{"errors":[{"code":"required","parameter":"scl","reason":"is required"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+				RequestContext:        events.LambdaFunctionURLRequestContext{HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodPost}},
+				QueryStringParameters: map[string]string{"scaleLength": "600"},
+				Body:                  tt.body,
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.want}, response)
+		})
+	}
+}
+
+func Test_ShouldRefuseBodiesThatAreNeitherJSONNorScalaFiles(t *testing.T) {
+	// Given
+	request := events.LambdaFunctionURLRequest{
+		RequestContext:        events.LambdaFunctionURLRequestContext{HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodPost}},
+		Headers:               map[string]string{"content-type": "application/x-www-form-urlencoded"},
+		QueryStringParameters: map[string]string{"scaleLength": "600"},
+		Body:                  "tuningSystem=ptolemy",
+	}
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), request)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: `{"errors":[{"code":"invalid_type","parameter":"body","reason":"must be application/json, text/plain or multipart/form-data, not application/x-www-form-urlencoded"}]}`}, response)
+}
+
+func Test_ShouldExportTuningSystemAsScalaFile(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "ptolemy", "octaves": "3", "format": "scl"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, map[string]string{"Content-Type": "text/plain", "Content-Disposition": `attachment; filename="ptolemy.scl"`}, response.Headers)
+	assert.Equal(t, `! ptolemy.scl
+!
+Ptolemy's 5-limit intense diatonic scale in Ionian mode.
+ 7
+!
+ 9/8 Pythagorean (Greater) Major Second
+ 5/4 Major Third
+ 4/3 Perfect Fourth
+ 3/2 Perfect Fifth
+ 5/3 Major Sixth
+ 15/8 Just Major Seventh
+ 2/1 Perfect Octave
+`, response.Body)
+}
+
+func Test_exportedScalaFilesShouldReadBackAsTheSameScale(t *testing.T) {
+	for _, system := range tuningSystems.All() {
+		t.Run(system.ID, func(t *testing.T) {
+			// Given
+			v := newValidator(requiredArguments[system.ID])
+			v.parse(system.Parameters...)
+			fretboard := system.scale(1, v.args).fretboard(600, "mm")
+
+			// When
+			scale, err := parseScalaFile(renderSCL(fretboard, system.ID))
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, len(fretboard.Frets)-1, len(scale.intervals))
+			assert.InDelta(t, 1200, scale.period().toCents(), 0.001)
+			for i, interval := range scale.intervals {
+				assert.InDelta(t, fretboard.Frets[i+1].Position, 600-600/interval.value(), 0.006)
+			}
+		})
+	}
+}
+
+func Test_ShouldExportKeyboardMappingForTheScale(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "19", "format": "kbm", "referenceFrequency": "432"},
+	})
+
+	// Then
+	assert.Equal(t, `attachment; filename="equal.kbm"`, response.Headers["Content-Disposition"])
+	assert.Contains(t, response.Body, "! Size of map:\n19\n! First MIDI note number to retune:\n0\n! Last MIDI note number to retune:\n127\n")
+	assert.Contains(t, response.Body, "! Middle note where the first entry of the mapping is mapped to:\n60\n")
+	assert.Contains(t, response.Body, "! Reference note for which frequency is given:\n69\n! Frequency to tune the above note to:\n432.000000\n")
+	assert.Contains(t, response.Body, "! Scale degree to consider as formal octave:\n19\n! Mapping.\n0\n1\n")
+	assert.True(t, strings.HasSuffix(response.Body, "\n17\n18\n"))
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mikebharris/music/instruments"
+	"github.com/mikebharris/music/music"
+)
+
+// scaleInterval is an interval above the open string, given either as a just ratio or as a size in cents.
+type scaleInterval struct {
+	ratio music.JustInterval
+	cents float64
+	just  bool
+}
+
+var unison = scaleInterval{ratio: music.Unison(), just: true}
+
+// parseScaleInterval accepts ratios such as 7:6 or 7/6, and treats anything else as a size in cents.
+func parseScaleInterval(s string) (scaleInterval, error) {
+	s = strings.TrimSpace(s)
+	if numerator, denominator, ok := strings.Cut(strings.Replace(s, "/", ":", 1), ":"); ok {
+		n, err := strconv.ParseUint(strings.TrimSpace(numerator), 10, 32)
+		if err != nil {
+			return scaleInterval{}, err
+		}
+		d, err := strconv.ParseUint(strings.TrimSpace(denominator), 10, 32)
+		if err != nil || n == 0 || d == 0 {
+			return scaleInterval{}, fmt.Errorf("invalid ratio %s", s)
+		}
+		return scaleInterval{ratio: music.NewInterval(uint(n), uint(d)), just: true}, nil
+	}
+	cents, err := parseFiniteFloat(s)
+	if err != nil {
+		return scaleInterval{}, fmt.Errorf("invalid interval %s", s)
+	}
+	return scaleInterval{cents: cents}, nil
+}
+
+func (i scaleInterval) toCents() float64 {
+	if i.just {
+		return i.ratio.ToCents()
+	}
+	return i.cents
+}
+
+func (i scaleInterval) value() float64 {
+	if i.just {
+		return i.ratio.ToFloat()
+	}
+	return math.Pow(2, i.cents/1200)
+}
+
+// plus stacks two intervals, staying just only when both of them are.
+func (i scaleInterval) plus(other scaleInterval) scaleInterval {
+	if i.just && other.just {
+		return scaleInterval{ratio: i.ratio.Add(other.ratio), just: true}
+	}
+	return scaleInterval{cents: i.toCents() + other.toCents()}
+}
+
+func (i scaleInterval) times(n int) scaleInterval {
+	result := unison
+	for range n {
+		result = result.plus(i)
+	}
+	return result
+}
+
+// raisedBy raises the interval by n periods, or lowers it when n is negative, staying just only when both are.
+func (i scaleInterval) raisedBy(period scaleInterval, n int) scaleInterval {
+	if n >= 0 {
+		return i.plus(period.times(n))
+	}
+	if !i.just || !period.just {
+		return scaleInterval{cents: i.toCents() + float64(n)*period.toCents()}
+	}
+	numerator, denominator := i.ratio.Numerator(), i.ratio.Denominator()
+	for range -n {
+		numerator, denominator = numerator*period.ratio.Denominator(), denominator*period.ratio.Numerator()
+	}
+	return scaleInterval{ratio: music.NewInterval(numerator, denominator), just: true}
+}
+
+func (i scaleInterval) String() string {
+	if i.just {
+		return i.ratio.String()
+	}
+	return formatFloat(i.cents)
+}
+
+func (i scaleInterval) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// fretScale is the frets of a tuning system as the intervals they sound above the open string, from which fretboards
+// of any scale length are laid out.
+type fretScale struct {
+	system      string
+	description string
+	frets       []scaleFret
+}
+
+// scaleFret is a fret of a scale, labelled as the music module labels the frets of its fretboards.
+type scaleFret struct {
+	label    string
+	comment  string
+	interval string
+	pitch    scaleInterval
+}
+
+// fretboard places the frets of the scale on a string of the given length, to the precision of its units.
+func (s fretScale) fretboard(scaleLength float64, units string) instruments.Fretboard {
+	fretboard := instruments.Fretboard{System: s.system, Description: s.description, ScaleLength: scaleLength}
+	for _, fret := range s.frets {
+		fretboard.Frets = append(fretboard.Frets, instruments.Fret{
+			Label:    fret.label,
+			Position: roundToPrecisionOf(fret.pitch.positionOn(scaleLength), units),
+			Comment:  fret.comment,
+			Interval: fret.interval,
+		})
+	}
+	return fretboard
+}
+
+// ratios gives the frequency ratio of each fret to the open string.
+func (s fretScale) ratios() []float64 {
+	var ratios []float64
+	for _, fret := range s.frets {
+		ratios = append(ratios, fret.pitch.value())
+	}
+	return ratios
+}
+
+// positionOn gives the distance from the nut at which a string of the given length sounds the interval, worked out for
+// just ratios as the music module does, so that positions are the same to the last digit.
+func (i scaleInterval) positionOn(scaleLength float64) float64 {
+	if i.just {
+		return scaleLength - scaleLength/float64(i.ratio.Numerator())*float64(i.ratio.Denominator())
+	}
+	return scaleLength - scaleLength/i.value()
+}
+
+// newJustScale lays out one of the music module's just scales as the module does on its fretboards.
+func newJustScale(periods int, scale music.JustScale) fretScale {
+	s := fretScale{system: scale.System(), description: "Fret positions based on " + scale.Description()}
+	octave := scaleInterval{ratio: music.Octave(), just: true}
+	previous := music.Unison()
+	for n := range periods {
+		for _, interval := range scale.Intervals() {
+			if n > 0 && interval == music.Unison() {
+				previous = music.Unison()
+				continue
+			}
+			s.frets = append(s.frets, scaleFret{
+				label:    interval.String(),
+				comment:  interval.Name(),
+				interval: interval.Subtract(previous).String(),
+				pitch:    octave.times(n).plus(scaleInterval{ratio: interval, just: true}),
+			})
+			previous = interval
+		}
+	}
+	return s
+}
+
+// newTemperedScale lays out one of the music module's tempered scales as the module does on its fretboards.
+func newTemperedScale(periods int, scale music.TemperedScale) fretScale {
+	s := fretScale{system: scale.System(), description: "Fret positions based on " + scale.Description()}
+	for n := range periods {
+		for i, interval := range scale.Intervals() {
+			if n > 0 && i == 0 {
+				continue
+			}
+			s.frets = append(s.frets, scaleFret{
+				label: fmt.Sprintf("%.2f cents", interval.ToCents()+float64(n)*1200),
+				// the module gives cents rounded to hundredths, but the ratio in full
+				pitch: scaleInterval{cents: 1200*math.Log2(interval.Value()) + float64(n)*1200},
+			})
+		}
+	}
+	return s
+}
+
+// newScaleFromIntervals lays out the frets of a scale given as ascending intervals within a period, repeated for the
+// given number of periods, in the same way as the music module lays out its just and tempered scales.
+func newScaleFromIntervals(system, description string, periods int, intervals []scaleInterval, period scaleInterval) fretScale {
+	s := fretScale{system: system, description: description}
+	// the open string is labelled like the period, so that tempered scales read in cents throughout
+	previous := unison
+	if !period.just {
+		previous = scaleInterval{}
+	}
+	s.frets = append(s.frets, newScaleFret(previous, previous))
+	base := unison
+	for range periods {
+		for _, interval := range slices.Concat(intervals, []scaleInterval{period}) {
+			current := base.plus(interval)
+			s.frets = append(s.frets, newScaleFret(current, previous))
+			previous = current
+		}
+		base = base.plus(period)
+	}
+	return s
+}
+
+func newScaleFret(interval, previous scaleInterval) scaleFret {
+	fret := scaleFret{label: fmt.Sprintf("%.2f cents", math.Round(interval.toCents()*100)/100), pitch: interval}
+	if interval.just {
+		fret.label = interval.ratio.String()
+		fret.comment = interval.ratio.Name()
+		if previous.just {
+			fret.interval = interval.ratio.Subtract(previous.ratio).String()
+		}
+	}
+	return fret
+}
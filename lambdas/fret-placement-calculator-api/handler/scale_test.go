@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mikebharris/music/instruments"
+	"github.com/mikebharris/music/music"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseScaleInterval(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    scaleInterval
+		wantErr bool
+	}{
+		{input: "7:6", want: scaleInterval{ratio: music.NewInterval(7, 6), just: true}},
+		{input: " 14/12 ", want: scaleInterval{ratio: music.NewInterval(7, 6), just: true}},
+		{input: "266.87", want: scaleInterval{cents: 266.87}},
+		{input: "700", want: scaleInterval{cents: 700}},
+		{input: "7:0", wantErr: true},
+		{input: "-7:6", wantErr: true},
+		{input: "seven", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseScaleInterval(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_scaleIntervalsShouldStayJustOnlyWhenStackedWithJustIntervals(t *testing.T) {
+	// Given
+	fifth := scaleInterval{ratio: music.PerfectFifth(), just: true}
+	tempered := scaleInterval{cents: 696.58}
+
+	// When
+	// Then
+	assert.Equal(t, scaleInterval{ratio: music.NewInterval(9, 4), just: true}, fifth.times(2))
+	assert.Equal(t, scaleInterval{cents: 1393.16}, tempered.times(2))
+	assert.False(t, fifth.plus(tempered).just)
+	assert.Equal(t, "3:2", fifth.String())
+	assert.Equal(t, "696.58", tempered.String())
+}
+
+func Test_newScaleFromIntervalsShouldMatchTheMusicModuleForJustScales(t *testing.T) {
+	// Given
+	ptolemy := instruments.NewFretboardFromJustScale(570, 1, music.NewIntenseDiatonicScale(music.IonianMode))
+	intervals := []scaleInterval{
+		{ratio: music.NewInterval(9, 8), just: true},
+		{ratio: music.NewInterval(5, 4), just: true},
+		{ratio: music.NewInterval(4, 3), just: true},
+		{ratio: music.NewInterval(3, 2), just: true},
+		{ratio: music.NewInterval(5, 3), just: true},
+		{ratio: music.NewInterval(15, 8), just: true},
+	}
+
+	// When
+	fretboard := newScaleFromIntervals("Custom", "", 1, intervals, scaleInterval{ratio: music.Octave(), just: true}).fretboard(570, "mm")
+
+	// Then
+	assert.Equal(t, ptolemy.Frets, fretboard.Frets)
+}
+
+func Test_newScaleFromIntervalsShouldMatchTheMusicModuleForTemperedScales(t *testing.T) {
+	// Given
+	equal := instruments.NewFretboardFromTemperedScale(650, 2, music.NewEqualTemperamentScale(12))
+	var intervals []scaleInterval
+	for i := 1; i < 12; i++ {
+		intervals = append(intervals, scaleInterval{cents: float64(i * 100)})
+	}
+
+	// When
+	fretboard := newScaleFromIntervals("Custom", "", 2, intervals, scaleInterval{cents: 1200}).fretboard(650, "mm")
+
+	// Then
+	assert.Equal(t, equal.Frets, fretboard.Frets)
+}
+
+func Test_newScaleFromIntervalsShouldRepeatAtThePeriod(t *testing.T) {
+	// Given
+	intervals := []scaleInterval{{ratio: music.NewInterval(7, 6), just: true}, {cents: 700}}
+	tritave := scaleInterval{ratio: music.NewInterval(3, 1), just: true}
+
+	// When
+	fretboard := newScaleFromIntervals("Custom", "", 2, intervals, tritave).fretboard(600, "mm")
+
+	// Then
+	assert.Equal(t, []instruments.Fret{
+		{Label: "1:1", Position: 0, Comment: "Perfect Unison", Interval: "1:1"},
+		{Label: "7:6", Position: 85.71, Interval: "7:6"},
+		{Label: "700.00 cents", Position: 199.55},
+		{Label: "3:1", Position: 400},
+		{Label: "7:2", Position: 428.57, Interval: "7:6"},
+		{Label: "2601.96 cents", Position: 466.52},
+		{Label: "9:1", Position: 533.33},
+	}, fretboard.Frets)
+}
+
+func Test_scalesOfTheMusicModuleShouldBeLaidOutAsTheModuleLaysThemOut(t *testing.T) {
+	for _, scale := range []music.JustScale{music.NewPythagoreanScale(), music.NewSazScale(), music.NewJustIntonationChromaticScaleWithLimit(7)} {
+		t.Run(scale.System(), func(t *testing.T) {
+			assert.Equal(t, instruments.NewFretboardFromJustScale(648, 3, scale), newJustScale(3, scale).fretboard(648, "mm"))
+		})
+	}
+	for _, scale := range []music.TemperedScale{music.NewQuarterCommaMeantoneScale(), music.NewBachWohltemperierteKlavierScale(), music.NewEqualTemperamentScale(31)} {
+		t.Run(scale.System(), func(t *testing.T) {
+			assert.Equal(t, instruments.NewFretboardFromTemperedScale(648, 3, scale), newTemperedScale(3, scale).fretboard(648, "mm"))
+		})
+	}
+}
+
+func Test_scalesShouldGiveTheExactRatiosOfTheirFrets(t *testing.T) {
+	// Given
+	scale := newScaleFromIntervals("Custom", "", 2, []scaleInterval{{ratio: music.NewInterval(5, 4), just: true}, {cents: 100000}}, scaleInterval{cents: 200000})
+
+	// When
+	ratios := scale.ratios()
+
+	// Then
+	assert.Equal(t, 1.25, ratios[1])
+	assert.InDelta(t, 1, math.Pow(2, 1000.0/12)/ratios[2], 1e-12)
+	assert.InDelta(t, 1, math.Pow(2, 4000.0/12)/ratios[6], 1e-12)
+}
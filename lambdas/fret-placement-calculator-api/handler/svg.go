@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/mikebharris/music/instruments"
+)
+
+// svgLayout holds the dimensions of the template, in the units of the scale length, so that the drawing prints at 1:1.
+type svgLayout struct {
+	units        string
+	margin       float64
+	boardWidth   float64
+	fontSize     float64
+	minorTick    float64
+	majorTick    float64
+	rulerSpacing float64
+}
+
+func newSVGLayout(units string) svgLayout {
+	switch units {
+	case "in":
+		return svgLayout{units: "in", margin: 0.5, boardWidth: defaultFretboardWidth(units), fontSize: 0.12, minorTick: 0.125, majorTick: 1, rulerSpacing: 0.5}
+	case "cm":
+		return svgLayout{units: "cm", margin: 1.2, boardWidth: defaultFretboardWidth(units), fontSize: 0.3, minorTick: 0.1, majorTick: 1, rulerSpacing: 1.2}
+	}
+	return svgLayout{units: "mm", margin: 12, boardWidth: defaultFretboardWidth(units), fontSize: 3, minorTick: 1, majorTick: 10, rulerSpacing: 12}
+}
+
+func renderSVG(fretboard instruments.Fretboard, units string) string {
+	l := newSVGLayout(units)
+	width := fretboard.ScaleLength + 2*l.margin
+	height := l.boardWidth + 2*l.margin + l.rulerSpacing
+	top, bottom := l.margin, l.margin+l.boardWidth
+	x := func(position float64) float64 { return l.margin + position }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s%s" height="%s%s" viewBox="0 0 %s %s">`+"\n", formatFloat(width), l.units, formatFloat(height), l.units, formatFloat(width), formatFloat(height))
+	fmt.Fprintf(&b, `<title>%s</title>`+"\n", html.EscapeString(fretboard.System))
+	fmt.Fprintf(&b, `<desc>%s</desc>`+"\n", html.EscapeString(fretboard.Description))
+	fmt.Fprintf(&b, `<g font-family="sans-serif" font-size="%s" stroke="black" stroke-width="%s">`+"\n", formatFloat(l.fontSize), formatFloat(l.fontSize/15))
+
+	fmt.Fprintf(&b, `<text x="%s" y="%s" stroke="none">%s, scale length %s%s</text>`+"\n", formatFloat(l.margin), formatFloat(l.margin/2), html.EscapeString(fretboard.System), formatFloat(fretboard.ScaleLength), l.units)
+	fmt.Fprintf(&b, `<line id="centre" x1="%s" y1="%s" x2="%s" y2="%s" stroke-dasharray="%s"/>`+"\n", formatFloat(x(0)), formatFloat(top+l.boardWidth/2), formatFloat(x(fretboard.ScaleLength)), formatFloat(top+l.boardWidth/2), formatFloat(l.fontSize))
+	fmt.Fprintf(&b, `<line id="nut" x1="%s" y1="%s" x2="%s" y2="%s" stroke-width="%s"/>`+"\n", formatFloat(x(0)), formatFloat(top), formatFloat(x(0)), formatFloat(bottom), formatFloat(l.fontSize/3))
+	for i, fret := range fretboard.Frets {
+		if fret.Position == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<line id="fret-%d" x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n", i, formatFloat(x(fret.Position)), formatFloat(top), formatFloat(x(fret.Position)), formatFloat(bottom))
+		fmt.Fprintf(&b, `<text x="%s" y="%s" stroke="none" text-anchor="middle">%d</text>`+"\n", formatFloat(x(fret.Position)), formatFloat(top-l.fontSize/2), i)
+		fmt.Fprintf(&b, `<text x="%s" y="%s" stroke="none" transform="rotate(-90 %s %s)">%s</text>`+"\n", formatFloat(x(fret.Position)-l.fontSize/3), formatFloat(bottom-l.fontSize/2), formatFloat(x(fret.Position)-l.fontSize/3), formatFloat(bottom-l.fontSize/2), html.EscapeString(fret.Label))
+	}
+	fmt.Fprintf(&b, `<line id="bridge" x1="%s" y1="%s" x2="%s" y2="%s" stroke-width="%s"/>`+"\n", formatFloat(x(fretboard.ScaleLength)), formatFloat(top), formatFloat(x(fretboard.ScaleLength)), formatFloat(bottom), formatFloat(l.fontSize/3))
+	b.WriteString(renderSVGRuler(l, fretboard.ScaleLength, bottom+l.rulerSpacing/2))
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
+
+func renderSVGRuler(l svgLayout, length float64, y float64) string {
+	var b strings.Builder
+	b.WriteString(`<g id="ruler">` + "\n")
+	fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n", formatFloat(l.margin), formatFloat(y), formatFloat(l.margin+length), formatFloat(y))
+	ticksPerMajor := int(math.Round(l.majorTick / l.minorTick))
+	for i := 0; float64(i)*l.minorTick <= length; i++ {
+		tick := l.fontSize / 2
+		if i%(ticksPerMajor/2) == 0 {
+			tick = l.fontSize
+		}
+		if i%ticksPerMajor == 0 {
+			tick = l.fontSize * 1.5
+			fmt.Fprintf(&b, `<text x="%s" y="%s" stroke="none" text-anchor="middle">%d</text>`+"\n", formatFloat(l.margin+float64(i)*l.minorTick), formatFloat(y+tick+l.fontSize), i/ticksPerMajor)
+		}
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n", formatFloat(l.margin+float64(i)*l.minorTick), formatFloat(y), formatFloat(l.margin+float64(i)*l.minorTick), formatFloat(y+tick))
+	}
+	b.WriteString("</g>\n")
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", v), "0"), ".")
+}
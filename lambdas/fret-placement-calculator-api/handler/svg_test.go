@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnSVGTemplateWhenFormatIsSVG(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "12", "format": "svg"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "image/svg+xml", response.Headers["Content-Type"])
+	assert.Contains(t, response.Body, `width="624mm" height="86mm" viewBox="0 0 624 86"`)
+	assert.Contains(t, response.Body, `<line id="nut" x1="12" y1="12" x2="12" y2="62"`)
+	assert.Contains(t, response.Body, `<line id="fret-1" x1="45.68" y1="12" x2="45.68" y2="62"/>`)
+	assert.Contains(t, response.Body, `<line id="fret-12" x1="312" y1="12" x2="312" y2="62"/>`)
+	assert.Contains(t, response.Body, `<line id="bridge" x1="612" y1="12" x2="612" y2="62"`)
+	assert.Contains(t, response.Body, `>100.00 cents</text>`)
+	assert.Contains(t, response.Body, `<g id="ruler">`)
+	assert.Equal(t, 12, strings.Count(response.Body, `<line id="fret-`))
+}
+
+func Test_ShouldReturnSVGTemplateInCentimetres(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "60", "units": "cm", "tuningSystem": "equal", "divisions": "12", "format": "svg"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Contains(t, response.Body, `width="62.4cm" height="8.6cm" viewBox="0 0 62.4 8.6"`)
+	assert.Contains(t, response.Body, `<line id="fret-1" x1="4.568" y1="1.2" x2="4.568" y2="6.2"/>`)
+}
+
+func Test_ShouldReturnSVGTemplateInInchesWhenRequestedThroughAcceptHeader(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		Headers:               map[string]string{"accept": "image/svg+xml"},
+		QueryStringParameters: map[string]string{"scaleLength": "25.5", "tuningSystem": "ptolemy", "units": "in"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "image/svg+xml", response.Headers["Content-Type"])
+	assert.Contains(t, response.Body, `width="26.5in" height="3.5in" viewBox="0 0 26.5 3.5"`)
+	assert.Contains(t, response.Body, `<line id="fret-7" x1="13.25" y1="0.5" x2="13.25" y2="2.5"/>`)
+	assert.Contains(t, response.Body, `>15:8</text>`)
+}
+
+func Test_ShouldReturnErrorWhenFormatOrUnitsAreInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "unknown format",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "saz", "format": "bmp"},
+			body:  `{"errors":[{"code":"not_allowed","parameter":"format","reason":"must be one of the allowed values","allowedValues":["json","csv","tsv","svg","dxf","gcode","pdf","scl","kbm"]}]}`,
+		},
+		{
+			name:  "unknown units",
+			query: map[string]string{"scaleLength": "600", "tuningSystem": "saz", "format": "svg", "units": "furlongs"},
+			body:  `{"errors":[{"code":"not_allowed","parameter":"units","reason":"must be one of the allowed values","allowedValues":["mm","cm","in"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
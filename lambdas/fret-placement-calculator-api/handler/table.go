@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"github.com/mikebharris/music/instruments"
+)
+
+// renderDelimitedTable writes a row for each fret, with its distance from the nut to a fraction of an inch and the
+// rounding error of that too if fractional positions are given.
+func renderDelimitedTable(fretboard instruments.Fretboard, delimiter rune, units string, fractions []FractionalPosition) string {
+	length := LengthFormat(units)
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = delimiter
+	heading := []string{"fret", "label", "distanceFromNut", "distanceFromPreviousFret", "distanceToBridge", "interval", "comment"}
+	if fractions != nil {
+		heading = append(heading, "fractionalDistanceFromNut", "roundingError")
+	}
+	_ = w.Write(heading)
+
+	previous := 0.0
+	for i, fret := range fretboard.Frets {
+		row := []string{
+			fmt.Sprintf("%d", i),
+			fret.Label,
+			fmt.Sprintf(length, fret.Position),
+			fmt.Sprintf(length, fret.Position-previous),
+			fmt.Sprintf(length, fretboard.ScaleLength-fret.Position),
+			fret.Interval,
+			fret.Comment,
+		}
+		if fractions != nil {
+			row = append(row, fractions[i].Position, fmt.Sprintf("%.4f", fractions[i].RoundingError))
+		}
+		_ = w.Write(row)
+		previous = fret.Position
+	}
+	w.Flush()
+	return b.String()
+}
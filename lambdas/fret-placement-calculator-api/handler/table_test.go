@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShouldReturnFretTableAsCSV(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "540", "tuningSystem": "ptolemy", "format": "csv"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/csv", response.Headers["Content-Type"])
+	assert.Equal(t, `fret,label,distanceFromNut,distanceFromPreviousFret,distanceToBridge,interval,comment
+0,1:1,0.00,0.00,540.00,1:1,Perfect Unison
+1,9:8,60.00,60.00,480.00,9:8,Pythagorean (Greater) Major Second
+2,5:4,108.00,48.00,432.00,10:9,Major Third
+3,4:3,135.00,27.00,405.00,16:15,Perfect Fourth
+4,3:2,180.00,45.00,360.00,9:8,Perfect Fifth
+5,5:3,216.00,36.00,324.00,10:9,Major Sixth
+6,15:8,252.00,36.00,288.00,9:8,Just Major Seventh
+7,2:1,270.00,18.00,270.00,16:15,Perfect Octave
+`, response.Body)
+}
+
+func Test_ShouldReturnFretTableAsTSV(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "600", "tuningSystem": "equal", "divisions": "2", "format": "tsv"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/tab-separated-values", response.Headers["Content-Type"])
+	assert.Equal(t, "fret\tlabel\tdistanceFromNut\tdistanceFromPreviousFret\tdistanceToBridge\tinterval\tcomment\n"+
+		"0\t0.00 cents\t0.00\t0.00\t600.00\t\t\n"+
+		"1\t600.00 cents\t175.74\t175.74\t424.26\t\t\n"+
+		"2\t1200.00 cents\t300.00\t124.26\t300.00\t\t\n", response.Body)
+}
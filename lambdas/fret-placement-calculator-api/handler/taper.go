@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/mikebharris/music/instruments"
+)
+
+// taper describes the width of a fretboard that narrows (or widens) linearly from the nut to the heel, which is taken
+// to be at the last fret.  A fretboardWidth on its own gives a fretboard with parallel sides.
+type taper struct {
+	nutWidth  float64
+	heelWidth float64
+	length    float64
+}
+
+func defaultFretboardWidth(units string) float64 {
+	switch units {
+	case "in":
+		return 2
+	case "cm":
+		return 5
+	default:
+		return 50
+	}
+}
+
+var taperParameters = []Parameter{
+	{Name: "fretboardWidth", Type: NumberParameter, Description: "Width of a fretboard with parallel sides (defaults to 50mm, 5cm or 2in)", ExclusiveMinimum: bound(0)},
+	{Name: "fretboardNutWidth", Type: NumberParameter, Description: "Width of the fretboard at the nut (defaults to fretboardWidth)", ExclusiveMinimum: bound(0)},
+	{Name: "fretboardHeelWidth", Type: NumberParameter, Description: "Width of the fretboard at the last fret (defaults to fretboardNutWidth)", ExclusiveMinimum: bound(0)},
+}
+
+func newTaper(args arguments, fretboard instruments.Fretboard, units string) taper {
+	t := taper{length: fretboard.ScaleLength}
+	if len(fretboard.Frets) > 0 && fretboard.Frets[len(fretboard.Frets)-1].Position > 0 {
+		t.length = fretboard.Frets[len(fretboard.Frets)-1].Position
+	}
+	t.nutWidth = args.numberOr("fretboardNutWidth", args.numberOr("fretboardWidth", defaultFretboardWidth(units)))
+	t.heelWidth = args.numberOr("fretboardHeelWidth", t.nutWidth)
+	return t
+}
+
+func (t taper) widthAt(position float64) float64 {
+	return t.nutWidth + (t.heelWidth-t.nutWidth)*position/t.length
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	newtonsPerKilogramForce = 9.80665
+	newtonsPerPoundForce    = 4.4482216152605
+)
+
+// StringTension gives the tension of an open string tuned to its pitch, with a warning if it is too slack or too tight
+// to play.
+type StringTension struct {
+	String           int     `json:"string"`
+	OpenString       string  `json:"openString"`
+	Frequency        float64 `json:"frequency"`
+	Material         string  `json:"material"`
+	Gauge            float64 `json:"gauge,omitempty"`
+	UnitWeight       float64 `json:"unitWeight"`
+	Tension          float64 `json:"tension"`
+	TensionKilograms float64 `json:"tensionKilograms"`
+	TensionPounds    float64 `json:"tensionPounds"`
+	Warning          string  `json:"warning,omitempty"`
+}
+
+// StringTensions gives the tension of every string of an instrument, in newtons and in kilograms and pounds of force,
+// and the total load they put on the neck.
+type StringTensions struct {
+	ScaleLength           float64         `json:"scaleLength"`
+	Units                 string          `json:"units"`
+	Strings               []StringTension `json:"strings"`
+	TotalTension          float64         `json:"totalTension"`
+	TotalTensionKilograms float64         `json:"totalTensionKilograms"`
+	TotalTensionPounds    float64         `json:"totalTensionPounds"`
+}
+
+var tensionParameters = []Parameter{
+	{Name: "openStrings", Type: PitchListParameter, Description: "Comma-separated pitches of the open strings, as note names and octaves (E4,B3,G3) or frequencies in Hz", Required: true},
+	{Name: "gauges", Type: NumberListParameter, Description: "Comma-separated diameters of the strings, in the units of the scale length; needed unless unitWeights are given", ExclusiveMinimum: bound(0)},
+	{Name: "materials", Type: StringListParameter, Description: "Comma-separated materials of the strings, one for every string or one for them all", Default: []string{"plainSteel"}, AllowedValues: stringMaterialIDs()},
+	{Name: "unitWeights", Type: NumberListParameter, Description: "Comma-separated masses per metre of the strings in kg/m, in place of gauges and the densities of their materials", ExclusiveMinimum: bound(0)},
+	{Name: "referenceFrequency", Type: NumberParameter, Description: "Frequency of A4 in Hz", Default: defaultReferenceFrequency, ExclusiveMinimum: bound(0)},
+}
+
+func (h Handler) handleTensionRequest(q map[string]string) events.LambdaFunctionURLResponse {
+	v := newValidator(q)
+	for _, p := range commonParameters() {
+		if p.Name == "scaleLength" || p.Name == "units" {
+			v.parse(p)
+		}
+	}
+	v.parse(tensionParameters...)
+	if v.valid() {
+		v.checkStringCounts()
+	}
+	if !v.valid() {
+		return v.errorResponse()
+	}
+	return jsonResponse(newStringTensions(v.args, strings.Split(v.q["openStrings"], ",")))
+}
+
+// checkStringCounts makes sure that there is a gauge or a unit weight for every string, and a material for every
+// string unless one is given for them all.
+func (v *validator) checkStringCounts() {
+	numberOfStrings := len(v.args.pitches("openStrings"))
+	if !v.args.has("gauges") && !v.args.has("unitWeights") {
+		v.addError(ValidationError{Code: RequiredError, Parameter: "gauges", Reason: "is required unless unitWeights are given"})
+	}
+	for _, name := range []string{"gauges", "unitWeights"} {
+		if v.args.has(name) && len(v.args.numbers(name)) != numberOfStrings {
+			v.addError(ValidationError{Code: OutOfRangeError, Parameter: name, Reason: fmt.Sprintf("must give one value for each of the %d openStrings", numberOfStrings)})
+		}
+	}
+	if materials := v.args.texts("materials"); len(materials) != 1 && len(materials) != numberOfStrings {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "materials", Reason: fmt.Sprintf("must give one value for each of the %d openStrings, or one for them all", numberOfStrings)})
+	}
+}
+
+// newStringTensions works out the tension T = μ(2Lf)² of each string from its unit weight μ, its vibrating length L
+// and its frequency f.
+func newStringTensions(args arguments, openStrings []string) StringTensions {
+	units := args.text("units")
+	length := args.number("scaleLength") * metresPer(units)
+	tensions := StringTensions{ScaleLength: args.number("scaleLength"), Units: units, Strings: []StringTension{}}
+	total := 0.0
+	for i, openString := range args.pitches("openStrings") {
+		materials := args.texts("materials")
+		material := lookupStringMaterial(materials[min(i, len(materials)-1)])
+		s := StringTension{String: i + 1, OpenString: strings.TrimSpace(openStrings[i]), Material: material.id}
+		if args.has("unitWeights") {
+			s.UnitWeight = args.numbers("unitWeights")[i]
+		} else {
+			s.Gauge = args.numbers("gauges")[i]
+			s.UnitWeight = material.unitWeight(s.Gauge * metresPer(units))
+		}
+
+		frequency := openString.hz(args.number("referenceFrequency"))
+		tension := s.UnitWeight * math.Pow(2*length*frequency, 2)
+		s.Frequency = roundToHundredths(frequency)
+		s.UnitWeight = math.Round(s.UnitWeight*1e7) / 1e7
+		s.Tension = roundToHundredths(tension)
+		s.TensionKilograms = roundToHundredths(tension / newtonsPerKilogramForce)
+		s.TensionPounds = roundToHundredths(tension / newtonsPerPoundForce)
+		s.Warning = material.tensionWarning(tension, length)
+		tensions.Strings = append(tensions.Strings, s)
+		total += tension
+	}
+	tensions.TotalTension = roundToHundredths(total)
+	tensions.TotalTensionKilograms = roundToHundredths(total / newtonsPerKilogramForce)
+	tensions.TotalTensionPounds = roundToHundredths(total / newtonsPerPoundForce)
+	return tensions
+}
+
+func (m stringMaterial) tensionWarning(tension, scaleLength float64) string {
+	playable, bass := m.playableRange(scaleLength)
+	kind := m.id + " strings"
+	if bass {
+		kind = m.id + " bass strings"
+	}
+	switch {
+	case tension < playable.minimum:
+		return fmt.Sprintf("below the playable range of %s to %s N for %s, so likely to feel slack and buzz", formatFloat(playable.minimum), formatFloat(playable.maximum), kind)
+	case tension > playable.maximum:
+		return fmt.Sprintf("above the playable range of %s to %s N for %s, so likely to be hard to fret or to break", formatFloat(playable.minimum), formatFloat(playable.maximum), kind)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func tensionRequest(q map[string]string) events.LambdaFunctionURLRequest {
+	return events.LambdaFunctionURLRequest{RawPath: "/tension", QueryStringParameters: q}
+}
+
+func Test_ShouldCalculateTensionOfEveryStringAndTotalNeckLoad(t *testing.T) {
+	// Given
+	q := map[string]string{
+		"scaleLength": "25.5",
+		"units":       "in",
+		"openStrings": "E4,B3,G3,D3,A2,E2",
+		"gauges":      "0.010,0.013,0.017,0.026,0.036,0.046",
+		"materials":   "plainSteel,plainSteel,plainSteel,nickelWound,nickelWound,nickelWound",
+	}
+
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), tensionRequest(q))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var tensions StringTensions
+	_ = json.Unmarshal([]byte(response.Body), &tensions)
+	assert.Equal(t, "in", tensions.Units)
+	assert.Equal(t, 6, len(tensions.Strings))
+	assert.Equal(t, StringTension{String: 1, OpenString: "E4", Frequency: 329.63, Material: "plainSteel", Gauge: 0.01, UnitWeight: 0.0003978, Tension: 72.52, TensionKilograms: 7.4, TensionPounds: 16.3}, tensions.Strings[0])
+	assert.Equal(t, StringTension{String: 6, OpenString: "E2", Frequency: 82.41, Material: "nickelWound", Gauge: 0.046, UnitWeight: 0.0062187, Tension: 70.87, TensionKilograms: 7.23, TensionPounds: 15.93}, tensions.Strings[5])
+	assert.Equal(t, 435.49, tensions.TotalTension)
+	assert.Equal(t, 44.41, tensions.TotalTensionKilograms)
+	assert.Equal(t, 97.9, tensions.TotalTensionPounds)
+}
+
+func Test_ShouldCalculateTensionFromUnitWeights(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "650", "openStrings": "329.63Hz", "unitWeights": "0.000401", "materials": "nylon"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), tensionRequest(q))
+
+	// Then
+	var tensions StringTensions
+	_ = json.Unmarshal([]byte(response.Body), &tensions)
+	assert.Equal(t, StringTension{String: 1, OpenString: "329.63Hz", Frequency: 329.63, Material: "nylon", UnitWeight: 0.000401, Tension: 73.64, TensionKilograms: 7.51, TensionPounds: 16.55}, tensions.Strings[0])
+}
+
+func Test_ShouldWarnWhenStringTensionIsOutsidePlayableRange(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "24.75", "units": "in", "openStrings": "E4,E4,E4", "gauges": "0.008,0.010,0.015"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), tensionRequest(q))
+
+	// Then
+	var tensions StringTensions
+	_ = json.Unmarshal([]byte(response.Body), &tensions)
+	assert.Equal(t, "below the playable range of 45 to 135 N for plainSteel strings, so likely to feel slack and buzz", tensions.Strings[0].Warning)
+	assert.Equal(t, "", tensions.Strings[1].Warning)
+	assert.Equal(t, "above the playable range of 45 to 135 N for plainSteel strings, so likely to be hard to fret or to break", tensions.Strings[2].Warning)
+}
+
+func Test_ShouldReturnErrorsWhenStringsAreInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "missing gauges",
+			query: map[string]string{"scaleLength": "650", "openStrings": "E4"},
+			body:  `{"errors":[{"code":"required","parameter":"gauges","reason":"is required unless unitWeights are given"}]}`,
+		},
+		{
+			name:  "mismatched counts",
+			query: map[string]string{"scaleLength": "650", "openStrings": "E4,B3", "gauges": "0.25", "materials": "nylon,gut,gut"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"gauges","reason":"must give one value for each of the 2 openStrings"},{"code":"out_of_range","parameter":"materials","reason":"must give one value for each of the 2 openStrings, or one for them all"}]}`,
+		},
+		{
+			name:  "invalid values",
+			query: map[string]string{"openStrings": "E4,X", "unitWeights": "-1", "materials": "wire"},
+			body:  `{"errors":[{"code":"required","parameter":"scaleLength","reason":"is required"},{"code":"invalid_type","parameter":"openStrings","reason":"must be a comma-separated list of note names and octaves such as E2, F#3 or Bb1, or frequencies in Hz"},{"code":"not_allowed","parameter":"materials","reason":"each must be one of the allowed values","allowedValues":["plainSteel","nickelWound","phosphorBronzeWound","nylon","gut"]},{"code":"out_of_range","parameter":"unitWeights","reason":"each must be greater than 0"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), tensionRequest(tt.query))
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
+
+func Test_ShouldHoldBassStringsToTheTensionsOfBassStrings(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "34", "units": "in", "openStrings": "E1,A1,D2,G2", "gauges": "0.105,0.085,0.065,0.045", "materials": "nickelWound"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), tensionRequest(q))
+
+	// Then
+	var tensions StringTensions
+	_ = json.Unmarshal([]byte(response.Body), &tensions)
+	assert.Equal(t, 4, len(tensions.Strings))
+	for _, s := range tensions.Strings {
+		assert.Greater(t, s.Tension, 160.0)
+		assert.Equal(t, "", s.Warning)
+	}
+}
+
+func Test_ShouldWarnOfSlackStringsOnABassScale(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "864", "openStrings": "E2", "gauges": "0.8", "materials": "nickelWound"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), tensionRequest(q))
+
+	// Then
+	var tensions StringTensions
+	_ = json.Unmarshal([]byte(response.Body), &tensions)
+	assert.Equal(t, "below the playable range of 110 to 270 N for nickelWound bass strings, so likely to feel slack and buzz", tensions.Strings[0].Warning)
+}
@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mikebharris/music/music"
+)
+
+const (
+	IntegerParameter = "integer"
+	NumberParameter  = "number"
+	StringParameter  = "string"
+
+	IntervalParameter     = "interval"
+	IntervalListParameter = "intervalList"
+	ScalaParameter        = "scala"
+	PitchParameter        = "pitch"
+	PitchListParameter    = "pitchList"
+	NumberListParameter   = "numberList"
+	StringListParameter   = "stringList"
+)
+
+type Parameter struct {
+	Name             string   `json:"name"`
+	Type             string   `json:"type"`
+	Description      string   `json:"description"`
+	Required         bool     `json:"required,omitempty"`
+	Default          any      `json:"default,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	AllowedValues    []string `json:"allowedValues,omitempty"`
+}
+
+type TuningSystem struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  []Parameter `json:"parameters,omitempty"`
+	newScale    func(periods int, args arguments) fretScale
+	// validate optionally checks the parsed arguments against each other.
+	validate func(args arguments) *ValidationError
+}
+
+// scale gives the frets of the tuning system over a number of periods, or up to maximumPosition when that is given.
+func (s TuningSystem) scale(periods int, args arguments) fretScale {
+	if !args.has("maximumPosition") {
+		return s.newScale(periods, args)
+	}
+	scale, frets := s.scaleUpTo(args)
+	scale.frets = scale.frets[:frets]
+	return scale
+}
+
+// scaleUpTo lays out as many periods as it takes to pass maximumPosition, giving how many of their frets lie up to it,
+// and gives up once there are more than maximumFrets.  Frets are measured by their ratios, rather than their positions
+// rounded to the scale length given, so that every fretboard of the request has the same frets.
+func (s TuningSystem) scaleUpTo(args arguments) (fretScale, int) {
+	// with a little allowance, so that a fret lying on maximumPosition is kept
+	limit := args.number("maximumPosition")/args.number("scaleLength") + 1e-9
+	for periods := 1; ; periods *= 2 {
+		scale := s.newScale(periods, args)
+		ratios := scale.ratios()
+		if 1-1/ratios[len(ratios)-1] > limit || len(ratios) > maximumFrets {
+			frets := 0
+			for _, ratio := range ratios {
+				if 1-1/ratio <= limit {
+					frets++
+				}
+			}
+			return scale, frets
+		}
+	}
+}
+
+// arguments holds the values of parameters, parsed into the types they declare.
+type arguments map[string]any
+
+func (a arguments) has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+func (a arguments) integer(name string) int {
+	return a[name].(int)
+}
+
+func (a arguments) number(name string) float64 {
+	return a[name].(float64)
+}
+
+func (a arguments) numberOr(name string, fallback float64) float64 {
+	if !a.has(name) {
+		return fallback
+	}
+	return a.number(name)
+}
+
+func (a arguments) text(name string) string {
+	return a[name].(string)
+}
+
+func (a arguments) interval(name string) scaleInterval {
+	return a[name].(scaleInterval)
+}
+
+func (a arguments) intervals(name string) []scaleInterval {
+	return a[name].([]scaleInterval)
+}
+
+func (a arguments) pitch(name string) pitch {
+	return a[name].(pitch)
+}
+
+func (a arguments) pitches(name string) []pitch {
+	return a[name].([]pitch)
+}
+
+func (a arguments) numbers(name string) []float64 {
+	return a[name].([]float64)
+}
+
+func (a arguments) texts(name string) []string {
+	return a[name].([]string)
+}
+
+func (a arguments) scala(name string) scalaScale {
+	return a[name].(scalaScale)
+}
+
+type TuningSystemRegistry struct {
+	systems []TuningSystem
+}
+
+func NewTuningSystemRegistry(systems ...TuningSystem) *TuningSystemRegistry {
+	r := &TuningSystemRegistry{}
+	for _, system := range systems {
+		r.Register(system)
+	}
+	return r
+}
+
+func (r *TuningSystemRegistry) Register(system TuningSystem) {
+	if _, exists := r.Lookup(system.ID); exists {
+		panic(fmt.Sprintf("tuning system %s registered twice", system.ID))
+	}
+	r.systems = append(r.systems, system)
+}
+
+func (r *TuningSystemRegistry) Lookup(id string) (TuningSystem, bool) {
+	for _, system := range r.systems {
+		if system.ID == id {
+			return system, true
+		}
+	}
+	return TuningSystem{}, false
+}
+
+func (r *TuningSystemRegistry) All() []TuningSystem {
+	return slices.Clone(r.systems)
+}
+
+func (r *TuningSystemRegistry) IDs() []string {
+	var ids []string
+	for _, system := range r.systems {
+		ids = append(ids, system.ID)
+	}
+	return ids
+}
+
+func bound(f float64) *float64 {
+	return &f
+}
+
+// commonParameters are accepted alongside those of whichever tuning system is chosen.
+func commonParameters() []Parameter {
+	return []Parameter{
+		{Name: "scaleLength", Type: NumberParameter, Description: "The scale length from nut to bridge (saddle)", Required: true, ExclusiveMinimum: bound(0)},
+		{Name: "tuningSystem", Type: StringParameter, Description: "Tuning system to use", Required: true, AllowedValues: tuningSystems.IDs()},
+		{Name: "octaves", Type: IntegerParameter, Description: "Number of periods of frets to compute: octaves, unless the tuning system repeats at another interval", Default: defaultNumberOfOctaves, Minimum: bound(1), Maximum: bound(maximumPeriods)},
+		{Name: "format", Type: StringParameter, Description: "Response format", Default: "json", AllowedValues: formats()},
+		{Name: "units", Type: StringParameter, Description: "Units of the scale length and of every length in the response, used to draw templates at 1:1 scale", Default: "mm", AllowedValues: lengthUnits()},
+		{Name: "maximumPosition", Type: NumberParameter, Description: "Distance from the nut up to which to compute frets, in place of a number of periods", ExclusiveMinimum: bound(0)},
+	}
+}
+
+// checkMaximumPosition makes sure that frets asked for up to a distance from the nut fall short of the bridge.
+func (v *validator) checkMaximumPosition() {
+	if v.args.has("maximumPosition") && v.args.has("scaleLength") && v.args.number("maximumPosition") >= v.args.number("scaleLength") {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "maximumPosition", Reason: "must be less than the scaleLength"})
+	}
+}
+
+// parseTuningSystem parses the tuning system named in the arguments, and then its own parameters.
+func (v *validator) parseTuningSystem() (TuningSystem, bool) {
+	if !v.args.has("tuningSystem") {
+		return TuningSystem{}, false
+	}
+	system, _ := tuningSystems.Lookup(v.args.text("tuningSystem"))
+	numberOfErrors := len(v.errors)
+	v.parse(system.Parameters...)
+	if system.validate != nil && len(v.errors) == numberOfErrors {
+		if err := system.validate(v.args); err != nil {
+			v.addError(*err)
+		}
+	}
+	if len(v.errors) == numberOfErrors {
+		v.checkFretsUpTo(system)
+	}
+	return system, true
+}
+
+// checkFretsUpTo makes sure that frets asked for up to a distance from the nut are not too many to lay out, as they
+// may be for the smallest of steps.
+func (v *validator) checkFretsUpTo(system TuningSystem) {
+	if !v.args.has("maximumPosition") || !v.args.has("scaleLength") || v.args.number("maximumPosition") >= v.args.number("scaleLength") {
+		return
+	}
+	if _, frets := system.scaleUpTo(v.args); frets > maximumFrets {
+		v.addError(ValidationError{Code: OutOfRangeError, Parameter: "maximumPosition", Reason: fmt.Sprintf("must be reached within %d frets", maximumFrets)})
+	}
+}
+
+var tuningSystems = NewTuningSystemRegistry(
+	TuningSystem{
+		ID:          "justFromRatios",
+		Name:        "Just Intonation",
+		Description: "Just Intonation chromatic scale derived from pure ratios up to a prime limit.",
+		Parameters: []Parameter{
+			{Name: "limit", Type: IntegerParameter, Description: "Prime limit of the ratios (3, 5, 7, 11, 13, etc)", Default: defaultJustLimit, Minimum: bound(2), Maximum: bound(31)},
+		},
+		newScale: func(periods int, args arguments) fretScale {
+			return newJustScale(periods, music.NewJustIntonationChromaticScaleWithLimit(args.integer("limit")))
+		},
+	},
+	TuningSystem{
+		ID:          "just5limitFromPythagorean",
+		Name:        "5-limit Pythagorean",
+		Description: "5-limit Just Intonation derived from tweaking the Pythagorean scale by a syntonic comma.",
+		newScale: func(periods int, _ arguments) fretScale {
+			return newJustScale(periods, music.New5LimitPythagoreanScale())
+		},
+	},
+	TuningSystem{
+		ID:          "meantone",
+		Name:        "Quarter-Comma Meantone",
+		Description: "Meantone temperament achieved by narrowing the fifths by a quarter of a syntonic comma.",
+		newScale: func(periods int, _ arguments) fretScale {
+			return newTemperedScale(periods, music.NewQuarterCommaMeantoneScale())
+		},
+	},
+	TuningSystem{
+		ID:          "extendedMeantone",
+		Name:        "Extended Quarter-Comma Meantone",
+		Description: "Quarter-comma meantone extended to 19 notes to the octave.",
+		newScale: func(periods int, _ arguments) fretScale {
+			return newTemperedScale(periods, music.NewExtendedQuarterCommaMeantoneScale())
+		},
+	},
+	TuningSystem{
+		ID:          "bachWellTemperament",
+		Name:        "Bach's Well-Tempered Tuning",
+		Description: "Bach's Well Temperament as decoded by Bradley Lehman.",
+		newScale: func(periods int, _ arguments) fretScale {
+			return newTemperedScale(periods, music.NewBachWohltemperierteKlavierScale())
+		},
+	},
+	TuningSystem{
+		ID:          "pythagorean",
+		Name:        "Pythagorean",
+		Description: "Pythagorean 3-limit just tuning.",
+		newScale: func(periods int, _ arguments) fretScale {
+			return newJustScale(periods, music.NewPythagoreanScale())
+		},
+	},
+	TuningSystem{
+		ID:          "equal",
+		Name:        "Equal Temperament",
+		Description: "Equal divisions of the octave or of any other interval, or equal steps of a given size.",
+		Parameters:  equalParameters,
+		newScale:    newEqualScale,
+	},
+	TuningSystem{
+		ID:          "ptolemy",
+		Name:        "Ptolemy Intense Diatonic",
+		Description: "Ptolemy's 5-limit intense diatonic scale in a choice of modes.",
+		Parameters: []Parameter{
+			{Name: "diatonicMode", Type: StringParameter, Description: "Musical mode of the diatonic scale", Default: music.IonianMode.String(), AllowedValues: []string{
+				music.IonianMode.String(), music.DorianMode.String(), music.PhrygianMode.String(), music.LydianMode.String(),
+				music.MixolydianMode.String(), music.AeolianMode.String(), music.LocrianMode.String(),
+			}},
+		},
+		newScale: func(periods int, args arguments) fretScale {
+			return newJustScale(periods, music.NewIntenseDiatonicScale(music.MusicalMode(args.text("diatonicMode"))))
+		},
+	},
+	TuningSystem{
+		ID:          "saz",
+		Name:        "Saz",
+		Description: "Turkish Saz tuning.",
+		newScale: func(periods int, _ arguments) fretScale {
+			return newJustScale(periods, music.NewSazScale())
+		},
+	},
+	TuningSystem{
+		ID:          "regular",
+		Name:        "Regular Temperament",
+		Description: "A chain of one interval, the generator, stacked up and down within a period, such as 1/3-comma meantone, Porcupine, Magic or Miracle.",
+		Parameters:  regularParameters,
+		newScale:    newRegularTemperamentScale,
+		validate:    validateRegularTemperament,
+	},
+	TuningSystem{
+		ID:          "custom",
+		Name:        "Custom",
+		Description: "Your own scale, given as ratios, sizes in cents or a mix of both, repeating at a period of your choice.",
+		Parameters: []Parameter{
+			{Name: "intervals", Type: IntervalListParameter, Description: "Comma-separated ascending intervals above the open string, as ratios (7:6) or cents (266.87), excluding the period", Required: true},
+			{Name: "period", Type: IntervalParameter, Description: "Interval at which the scale repeats, as a ratio or in cents", Default: scaleInterval{ratio: music.Octave(), just: true}},
+		},
+		newScale: func(periods int, args arguments) fretScale {
+			intervals, period := args.intervals("intervals"), args.interval("period")
+			description := fmt.Sprintf("Fret positions based on a custom scale of %d steps repeating at %s.", len(intervals)+1, period)
+			return newScaleFromIntervals("Custom", description, periods, intervals, period)
+		},
+		validate: func(args arguments) *ValidationError {
+			intervals, period := args.intervals("intervals"), args.interval("period")
+			if intervals[len(intervals)-1].toCents() >= period.toCents() {
+				return &ValidationError{Code: OutOfRangeError, Parameter: "intervals", Reason: fmt.Sprintf("must all be smaller than the period of %s", period)}
+			}
+			return nil
+		},
+	},
+	TuningSystem{
+		ID:          "scala",
+		Name:        "Scala",
+		Description: "A scale from a Scala (.scl) file, POSTed as the request body or given in the scl parameter.",
+		Parameters: []Parameter{
+			{Name: "scl", Type: ScalaParameter, Description: "Contents of a Scala (.scl) scale file", Required: true},
+		},
+		newScale: func(periods int, args arguments) fretScale {
+			return args.scala("scl").scale(periods)
+		},
+	},
+)
+
+// TuningSystems lists the registered tuning systems, so that other front ends such as the command-line interface can
+// offer the same choice as the service.
+func TuningSystems() []TuningSystem {
+	return tuningSystems.All()
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requiredArguments supplies the parameters that tuning systems have no sensible default for.
+var requiredArguments = map[string]map[string]string{
+	"regular": {"temperament": "porcupine"},
+	"custom":  {"intervals": "9:8,5:4,4:3,3:2,5:3,15:8"},
+	"scala":   {"scl": "Pentatonic\n 5\n9/8\n5/4\n3/2\n5/3\n2/1\n"},
+}
+
+func Test_everyRegisteredTuningSystemShouldBuildAFretboard(t *testing.T) {
+	for _, system := range tuningSystems.All() {
+		t.Run(system.ID, func(t *testing.T) {
+			v := newValidator(requiredArguments[system.ID])
+			v.parse(system.Parameters...)
+			assert.True(t, v.valid())
+
+			fretboard := system.scale(1, v.args).fretboard(600, "mm")
+			assert.Equal(t, 600.0, fretboard.ScaleLength)
+			assert.NotEmpty(t, fretboard.System)
+			assert.Greater(t, len(fretboard.Frets), 1)
+		})
+	}
+}
+
+func Test_shouldLookUpRegisteredTuningSystems(t *testing.T) {
+	// Given
+	registry := NewTuningSystemRegistry(TuningSystem{ID: "drone", Name: "Drone"})
+
+	// When
+	system, ok := registry.Lookup("drone")
+	_, missing := registry.Lookup("chromatic")
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, "Drone", system.Name)
+	assert.False(t, missing)
+	assert.Equal(t, 1, len(registry.All()))
+}
+
+func Test_shouldRefuseToRegisterTheSameTuningSystemTwice(t *testing.T) {
+	// Given
+	registry := NewTuningSystemRegistry(TuningSystem{ID: "drone"})
+
+	// When
+	// Then
+	assert.Panics(t, func() { registry.Register(TuningSystem{ID: "drone"}) })
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+)
+
+func lengthUnits() []string {
+	return []string{"mm", "cm", "in"}
+}
+
+func metresPer(units string) float64 {
+	switch units {
+	case "in":
+		return 0.0254
+	case "cm":
+		return 0.01
+	default:
+		return 0.001
+	}
+}
+
+// roundToPrecisionOf rounds lengths to hundredths of a millimetre, or thousandths of a centimetre or an inch.
+func roundToPrecisionOf(length float64, units string) float64 {
+	if units == "cm" || units == "in" {
+		return math.Round(length*1000)/1000 + 0 // adding zero turns -0 into 0
+	}
+	return roundToHundredths(length)
+}
+
+// LengthFormat writes lengths to the precision that roundToPrecisionOf rounds them to.
+func LengthFormat(units string) string {
+	if units == "cm" || units == "in" {
+		return "%.3f"
+	}
+	return "%.2f"
+}
+
+// FractionalPosition is the distance of a fret from the nut rounded to the nearest fraction of an inch, as marked on
+// an imperial rule, with how far in inches the rounded distance lies beyond the exact one.
+type FractionalPosition struct {
+	Fret          int     `json:"fret"`
+	Position      string  `json:"position"`
+	RoundingError float64 `json:"roundingError"`
+}
+
+var fractionParameters = []Parameter{
+	{Name: "fraction", Type: IntegerParameter, Description: "Also give positions to the nearest fraction of an inch with this denominator, such as 64 for 1/64\"; needs units of in", Minimum: bound(2), Maximum: bound(1024)},
+}
+
+// checkFractionUnits rejects fractions of an inch for positions that aren't in inches.
+func (v *validator) checkFractionUnits() {
+	if v.args.has("fraction") && v.args.text("units") != "in" {
+		v.addError(ValidationError{Code: NotAllowedError, Parameter: "fraction", Reason: "is only allowed when units is in"})
+	}
+}
+
+// fractionalPositions works out the exact distance of each fret from the nut, rather than the distance rounded to
+// hundredths, as a rounding error smaller than that is worth reporting.
+func fractionalPositionsIfAsked(args arguments, scale fretScale) []FractionalPosition {
+	if !args.has("fraction") {
+		return nil
+	}
+	var positions []FractionalPosition
+	for i, ratio := range scale.ratios() {
+		position := args.number("scaleLength") * (1 - 1/ratio)
+		fraction, rounded := formatFractionalInches(position, args.integer("fraction"))
+		positions = append(positions, FractionalPosition{
+			Fret:          i,
+			Position:      fraction,
+			RoundingError: math.Round((rounded-position)*10000)/10000 + 0,
+		})
+	}
+	return positions
+}
+
+// formatFractionalInches writes a length in inches to the nearest 1/denominator, in lowest terms, such as 12 3/64",
+// also returning the length it was rounded to.
+func formatFractionalInches(inches float64, denominator int) (string, float64) {
+	numerator := int(math.Round(inches * float64(denominator)))
+	rounded := float64(numerator) / float64(denominator)
+	whole, numerator := numerator/denominator, numerator%denominator
+	if d := gcd(numerator, denominator); d > 1 {
+		numerator, denominator = numerator/d, denominator/d
+	}
+	switch {
+	case numerator == 0:
+		return fmt.Sprintf("%d\"", whole), rounded
+	case whole == 0:
+		return fmt.Sprintf("%d/%d\"", numerator, denominator), rounded
+	default:
+		return fmt.Sprintf("%d %d/%d\"", whole, numerator, denominator), rounded
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
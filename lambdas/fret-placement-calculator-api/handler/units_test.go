@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formatFractionalInches(t *testing.T) {
+	tests := []struct {
+		inches      float64
+		denominator int
+		want        string
+		rounded     float64
+	}{
+		{inches: 12.047, denominator: 64, want: `12 3/64"`, rounded: 12.046875},
+		{inches: 1.431205, denominator: 64, want: `1 7/16"`, rounded: 1.4375},
+		{inches: 0.5, denominator: 64, want: `1/2"`, rounded: 0.5},
+		{inches: 11.999, denominator: 64, want: `12"`, rounded: 12},
+		{inches: 0, denominator: 32, want: `0"`, rounded: 0},
+		{inches: 2.3, denominator: 10, want: `2 3/10"`, rounded: 2.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, rounded := formatFractionalInches(tt.inches, tt.denominator)
+			assert.Equal(t, tt.want, got)
+			assert.InDelta(t, tt.rounded, rounded, 1e-9)
+		})
+	}
+}
+
+func Test_roundToPrecisionOf(t *testing.T) {
+	assert.Equal(t, 1.23, roundToPrecisionOf(1.23456, "mm"))
+	assert.Equal(t, 1.235, roundToPrecisionOf(1.23456, "cm"))
+	assert.Equal(t, 1.235, roundToPrecisionOf(1.23456, "in"))
+}
+
+func Test_metresPer(t *testing.T) {
+	assert.Equal(t, 0.001, metresPer("mm"))
+	assert.Equal(t, 0.01, metresPer("cm"))
+	assert.Equal(t, 0.0254, metresPer("in"))
+}
+
+func Test_ShouldGiveUnitsAndFractionalInchPositionsOfEachFret(t *testing.T) {
+	// Given
+	// When
+	response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "equal", "divisions": "12", "fraction": "64"},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "in", fretboard.Units)
+	assert.Equal(t, 13, len(fretboard.FractionalPositions))
+	assert.Equal(t, FractionalPosition{Fret: 0, Position: `0"`}, fretboard.FractionalPositions[0])
+	assert.Equal(t, FractionalPosition{Fret: 1, Position: `1 7/16"`, RoundingError: 0.0063}, fretboard.FractionalPositions[1])
+	assert.Equal(t, FractionalPosition{Fret: 12, Position: `12 3/4"`}, fretboard.FractionalPositions[12])
+}
+
+func Test_ShouldAddFractionalInchPositionsToFretTable(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "equal", "divisions": "12", "fraction": "64", "format": "csv"},
+	})
+
+	// Then
+	lines := strings.Split(response.Body, "\n")
+	assert.Equal(t, "fret,label,distanceFromNut,distanceFromPreviousFret,distanceToBridge,interval,comment,fractionalDistanceFromNut,roundingError", lines[0])
+	assert.Equal(t, `1,100.00 cents,1.431,1.431,24.069,,,"1 7/16""",0.0063`, lines[2])
+}
+
+func Test_ShouldGiveFretPositionsInInchesToThousandths(t *testing.T) {
+	// Given
+	q := map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "equal", "divisions": "12"}
+	gcode := map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "equal", "divisions": "12", "format": "gcode", "slotDepth": "0.1", "feedRate": "20"}
+
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: q})
+	gcodeResponse, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: gcode})
+
+	// Then
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, 1.431, fretboard.Frets[1].Position)
+	assert.Equal(t, 12.75, fretboard.Frets[12].Position)
+	assert.Contains(t, gcodeResponse.Body, "G0 X1.431 ")
+}
+
+func Test_ShouldGiveFretPositionsInCentimetres(t *testing.T) {
+	// Given
+	// When
+	response, _ := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"scaleLength": "54", "units": "cm", "tuningSystem": "ptolemy"},
+	})
+
+	// Then
+	var fretboard DetailedFretboard
+	_ = json.Unmarshal([]byte(response.Body), &fretboard)
+	assert.Equal(t, "cm", fretboard.Units)
+	assert.Equal(t, 6.0, fretboard.Frets[1].Position)
+}
+
+func Test_ShouldOnlyAllowFractionsOfAnInchForPositionsInInches(t *testing.T) {
+	tests := []struct {
+		name  string
+		query map[string]string
+		body  string
+	}{
+		{
+			name:  "millimetres",
+			query: map[string]string{"scaleLength": "648", "tuningSystem": "saz", "fraction": "64"},
+			body:  `{"errors":[{"code":"not_allowed","parameter":"fraction","reason":"is only allowed when units is in"}]}`,
+		},
+		{
+			name:  "denominator too small",
+			query: map[string]string{"scaleLength": "25.5", "units": "in", "tuningSystem": "saz", "fraction": "1"},
+			body:  `{"errors":[{"code":"out_of_range","parameter":"fraction","reason":"must be between 2 and 1024"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler{}.HandleRequest(context.Background(), events.LambdaFunctionURLRequest{QueryStringParameters: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, events.LambdaFunctionURLResponse{StatusCode: http.StatusUnprocessableEntity, Headers: headers, Body: tt.body}, response)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	RequiredError    = "required"
+	InvalidTypeError = "invalid_type"
+	OutOfRangeError  = "out_of_range"
+	NotAllowedError  = "not_allowed"
+)
+
+type ValidationError struct {
+	Code          string   `json:"code"`
+	Parameter     string   `json:"parameter"`
+	Reason        string   `json:"reason"`
+	AllowedValues []string `json:"allowedValues,omitempty"`
+}
+
+type ValidationErrors struct {
+	Errors []ValidationError `json:"errors"`
+}
+
+// validator parses parameters into arguments, collecting every problem it finds so that they can all be reported in
+// a single response.
+type validator struct {
+	q      map[string]string
+	args   arguments
+	errors []ValidationError
+}
+
+func newValidator(q map[string]string) *validator {
+	v := &validator{q: map[string]string{}, args: arguments{}}
+	for key, value := range q {
+		v.q[key] = value
+	}
+	return v
+}
+
+func (v *validator) parse(parameters ...Parameter) {
+	for _, p := range parameters {
+		raw := v.q[p.Name]
+		if raw == "" {
+			if p.Required {
+				v.addError(ValidationError{Code: RequiredError, Parameter: p.Name, Reason: "is required", AllowedValues: p.AllowedValues})
+			} else if p.Default != nil {
+				v.args[p.Name] = p.Default
+			}
+			continue
+		}
+		value, err := p.parse(raw)
+		if err != nil {
+			v.addError(*err)
+			continue
+		}
+		v.args[p.Name] = value
+	}
+}
+
+func (v *validator) addError(err ValidationError) {
+	v.errors = append(v.errors, err)
+}
+
+func (v *validator) valid() bool {
+	return len(v.errors) == 0
+}
+
+func (v *validator) errorResponse() events.LambdaFunctionURLResponse {
+	return validationErrorResponse(v.errors...)
+}
+
+func validationErrorResponse(errors ...ValidationError) events.LambdaFunctionURLResponse {
+	response := jsonResponse(ValidationErrors{Errors: errors})
+	response.StatusCode = http.StatusUnprocessableEntity
+	return response
+}
+
+func (p Parameter) parse(raw string) (any, *ValidationError) {
+	switch p.Type {
+	case IntegerParameter:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be an integer"}
+		}
+		if err := p.checkRange(float64(i)); err != nil {
+			return nil, err
+		}
+		return i, nil
+	case NumberParameter:
+		f, err := parseFiniteFloat(raw)
+		if err != nil {
+			return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a number"}
+		}
+		if err := p.checkRange(f); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case IntervalParameter:
+		interval, err := parseScaleInterval(raw)
+		if err != nil {
+			return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a ratio such as 7:6 or a size in cents such as 266.87"}
+		}
+		if interval.toCents() <= 0 {
+			return nil, &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: "must be larger than 1:1"}
+		}
+		if interval.toCents() > maximumInterval {
+			return nil, &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: fmt.Sprintf("must be no larger than %d cents (%d:1)", maximumInterval, 1<<(maximumInterval/1200))}
+		}
+		return interval, nil
+	case IntervalListParameter:
+		var intervals []scaleInterval
+		previous := unison
+		for _, s := range strings.Split(raw, ",") {
+			interval, err := parseScaleInterval(s)
+			if err != nil {
+				return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a comma-separated list of ratios such as 7:6 or sizes in cents such as 266.87"}
+			}
+			if interval.toCents() <= previous.toCents() {
+				return nil, &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: "must be in ascending order and each larger than 1:1"}
+			}
+			if interval.toCents() > maximumInterval {
+				return nil, &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: fmt.Sprintf("must each be no larger than %d cents (%d:1)", maximumInterval, 1<<(maximumInterval/1200))}
+			}
+			intervals = append(intervals, interval)
+			previous = interval
+		}
+		return intervals, nil
+	case PitchParameter:
+		pitch, err := parsePitch(raw)
+		if err != nil {
+			return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a note name and octave such as E2, F#3 or Bb1, or a frequency in Hz"}
+		}
+		return pitch, nil
+	case PitchListParameter:
+		var pitches []pitch
+		for _, s := range strings.Split(raw, ",") {
+			pitch, err := parsePitch(s)
+			if err != nil {
+				return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a comma-separated list of note names and octaves such as E2, F#3 or Bb1, or frequencies in Hz"}
+			}
+			pitches = append(pitches, pitch)
+		}
+		return pitches, nil
+	case NumberListParameter:
+		var numbers []float64
+		for _, s := range strings.Split(raw, ",") {
+			f, err := parseFiniteFloat(strings.TrimSpace(s))
+			if err != nil {
+				return nil, &ValidationError{Code: InvalidTypeError, Parameter: p.Name, Reason: "must be a comma-separated list of numbers"}
+			}
+			if err := p.checkRange(f); err != nil {
+				err.Reason = "each " + err.Reason
+				return nil, err
+			}
+			numbers = append(numbers, f)
+		}
+		return numbers, nil
+	case StringListParameter:
+		var values []string
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			if len(p.AllowedValues) > 0 && !slices.Contains(p.AllowedValues, s) {
+				return nil, &ValidationError{Code: NotAllowedError, Parameter: p.Name, Reason: "each must be one of the allowed values", AllowedValues: p.AllowedValues}
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	case ScalaParameter:
+		return p.parseScala(raw)
+	default:
+		if len(p.AllowedValues) > 0 && !slices.Contains(p.AllowedValues, raw) {
+			return nil, &ValidationError{Code: NotAllowedError, Parameter: p.Name, Reason: "must be one of the allowed values", AllowedValues: p.AllowedValues}
+		}
+		return raw, nil
+	}
+}
+
+// parseFiniteFloat parses a number as strconv.ParseFloat does, but refuses NaN and the infinities, which no length,
+// frequency or interval can be.
+func parseFiniteFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%s is not a finite number", s)
+	}
+	return f, nil
+}
+
+func (p Parameter) checkRange(f float64) *ValidationError {
+	var reason string
+	switch {
+	case p.ExclusiveMinimum != nil && f <= *p.ExclusiveMinimum:
+		reason = fmt.Sprintf("must be greater than %s", formatFloat(*p.ExclusiveMinimum))
+	case p.Minimum != nil && p.Maximum != nil && (f < *p.Minimum || f > *p.Maximum):
+		reason = fmt.Sprintf("must be between %s and %s", formatFloat(*p.Minimum), formatFloat(*p.Maximum))
+	case p.Minimum != nil && f < *p.Minimum:
+		reason = fmt.Sprintf("must be at least %s", formatFloat(*p.Minimum))
+	case p.Maximum != nil && f > *p.Maximum:
+		reason = fmt.Sprintf("must be at most %s", formatFloat(*p.Maximum))
+	default:
+		return nil
+	}
+	return &ValidationError{Code: OutOfRangeError, Parameter: p.Name, Reason: reason}
+}
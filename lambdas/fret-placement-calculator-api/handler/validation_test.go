@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validatorShouldParseParametersIntoArguments(t *testing.T) {
+	parameters := []Parameter{
+		{Name: "steps", Type: IntegerParameter, Default: 7, Minimum: bound(1), Maximum: bound(10)},
+		{Name: "width", Type: NumberParameter, ExclusiveMinimum: bound(0)},
+		{Name: "flavour", Type: StringParameter, Default: "plain", AllowedValues: []string{"plain", "spicy"}},
+	}
+
+	tests := []struct {
+		name   string
+		q      map[string]string
+		want   arguments
+		errors []ValidationError
+	}{
+		{
+			name: "valid values",
+			q:    map[string]string{"steps": "3", "width": "2.5", "flavour": "spicy"},
+			want: arguments{"steps": 3, "width": 2.5, "flavour": "spicy"},
+		},
+		{
+			name: "missing values use defaults",
+			q:    map[string]string{},
+			want: arguments{"steps": 7, "flavour": "plain"},
+		},
+		{
+			name: "invalid values are all reported",
+			q:    map[string]string{"steps": "11", "width": "wide", "flavour": "sweet"},
+			want: arguments{},
+			errors: []ValidationError{
+				{Code: OutOfRangeError, Parameter: "steps", Reason: "must be between 1 and 10"},
+				{Code: InvalidTypeError, Parameter: "width", Reason: "must be a number"},
+				{Code: NotAllowedError, Parameter: "flavour", Reason: "must be one of the allowed values", AllowedValues: []string{"plain", "spicy"}},
+			},
+		},
+		{
+			name:   "integers must be whole numbers",
+			q:      map[string]string{"steps": "2.5", "width": "0"},
+			want:   arguments{"flavour": "plain"},
+			errors: []ValidationError{{Code: InvalidTypeError, Parameter: "steps", Reason: "must be an integer"}, {Code: OutOfRangeError, Parameter: "width", Reason: "must be greater than 0"}},
+		},
+		{
+			name:   "numbers must be finite",
+			q:      map[string]string{"width": "NaN"},
+			want:   arguments{"steps": 7, "flavour": "plain"},
+			errors: []ValidationError{{Code: InvalidTypeError, Parameter: "width", Reason: "must be a number"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			v := newValidator(tt.q)
+
+			// When
+			v.parse(parameters...)
+
+			// Then
+			assert.Equal(t, tt.want, v.args)
+			assert.Equal(t, tt.errors, v.errors)
+			assert.Equal(t, len(tt.errors) == 0, v.valid())
+		})
+	}
+}
+
+func Test_validationErrorResponseShouldBeUnprocessableEntity(t *testing.T) {
+	// Given
+	// When
+	response := validationErrorResponse(ValidationError{Code: RequiredError, Parameter: "scaleLength", Reason: "is required"})
+
+	// Then
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assert.Equal(t, `{"errors":[{"code":"required","parameter":"scaleLength","reason":"is required"}]}`, response.Body)
+}
+
+func Test_validatorShouldParseListParameters(t *testing.T) {
+	parameters := []Parameter{
+		{Name: "pitches", Type: PitchListParameter},
+		{Name: "widths", Type: NumberListParameter, ExclusiveMinimum: bound(0)},
+		{Name: "flavours", Type: StringListParameter, AllowedValues: []string{"plain", "spicy"}},
+	}
+
+	tests := []struct {
+		name   string
+		q      map[string]string
+		want   arguments
+		errors []ValidationError
+	}{
+		{
+			name: "valid values",
+			q:    map[string]string{"pitches": "A4, 220Hz", "widths": "1.5, 2", "flavours": "plain, spicy"},
+			want: arguments{"pitches": []pitch{{semitones: 0}, {frequency: 220}}, "widths": []float64{1.5, 2}, "flavours": []string{"plain", "spicy"}},
+		},
+		{
+			name: "every value is checked",
+			q:    map[string]string{"pitches": "A4,H2", "widths": "1,0", "flavours": "plain,sweet"},
+			want: arguments{},
+			errors: []ValidationError{
+				{Code: InvalidTypeError, Parameter: "pitches", Reason: "must be a comma-separated list of note names and octaves such as E2, F#3 or Bb1, or frequencies in Hz"},
+				{Code: OutOfRangeError, Parameter: "widths", Reason: "each must be greater than 0"},
+				{Code: NotAllowedError, Parameter: "flavours", Reason: "each must be one of the allowed values", AllowedValues: []string{"plain", "spicy"}},
+			},
+		},
+		{
+			name:   "numbers must be numbers",
+			q:      map[string]string{"widths": "1,wide"},
+			want:   arguments{},
+			errors: []ValidationError{{Code: InvalidTypeError, Parameter: "widths", Reason: "must be a comma-separated list of numbers"}},
+		},
+		{
+			name: "numbers must be finite",
+			q:    map[string]string{"pitches": "InfHz", "widths": "1,+Inf"},
+			want: arguments{},
+			errors: []ValidationError{
+				{Code: InvalidTypeError, Parameter: "pitches", Reason: "must be a comma-separated list of note names and octaves such as E2, F#3 or Bb1, or frequencies in Hz"},
+				{Code: InvalidTypeError, Parameter: "widths", Reason: "must be a comma-separated list of numbers"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			v := newValidator(tt.q)
+
+			// When
+			v.parse(parameters...)
+
+			// Then
+			assert.Equal(t, tt.want, v.args)
+			assert.Equal(t, tt.errors, v.errors)
+		})
+	}
+}
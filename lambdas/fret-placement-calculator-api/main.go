@@ -1,11 +1,22 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"net/http"
+
 	"main/lambdas/fret-placement-calculator-api/handler"
 
 	"github.com/aws/aws-lambda-go/lambda"
 )
 
 func main() {
+	listen := flag.String("listen", "", "serve plain HTTP on this address (e.g. :8080) instead of running as a Lambda function")
+	flag.Parse()
+
+	if *listen != "" {
+		log.Printf("listening on %s", *listen)
+		log.Fatal(http.ListenAndServe(*listen, handler.Handler{}))
+	}
 	lambda.Start(handler.Handler{}.HandleRequest)
 }